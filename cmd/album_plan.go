@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/i18n"
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// Album plan actions
+const (
+	planActionCreate   = "create"   // Create a new album
+	planActionRecreate = "recreate" // Delete the existing album and create a new one
+	planActionSync     = "sync"     // Update the existing album in place
+	planActionSkip     = "skip"     // Album exists and is left alone
+	planActionExclude  = "exclude"  // Trip is excluded from album creation
+)
+
+// albumPlan is everything create-albums would do, in a form that can be reviewed and applied later
+type albumPlan struct {
+	CreatedAt time.Time        `json:"created_at"`
+	Albums    []albumPlanEntry `json:"albums"`
+}
+
+// albumPlanEntry is the planned action for one trip
+type albumPlanEntry struct {
+	TripID      int64    `json:"trip_id"`
+	Action      string   `json:"action"`
+	AlbumID     string   `json:"album_id,omitempty"` // Existing album, if any
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	AssetCount  int      `json:"asset_count"`
+	AssetIDs    []string `json:"asset_ids"`
+}
+
+// buildAlbumPlan decides what to do for every trip without contacting Immich. Photos inside
+// privacy zones are left out of the plan.
+func buildAlbumPlan(trips []models.Trip, recreate, sync bool, catalog *i18n.Catalog, privacy *albumPrivacy) albumPlan {
+	plan := albumPlan{CreatedAt: time.Now()}
+
+	withheld := 0
+	for _, trip := range trips {
+		trip, n := privacy.withhold(trip)
+		withheld += n
+		entry := albumPlanEntry{
+			TripID:      trip.ID,
+			AlbumID:     trip.AlbumID,
+			Name:        trip.Name,
+			Description: albumDescription(trip, catalog),
+			AssetCount:  len(trip.AssetIDs),
+			AssetIDs:    trip.AssetIDs,
+		}
+
+		switch {
+		case trip.ExcludeFromAlbum:
+			entry.Action = planActionExclude
+		case trip.AlbumID == "":
+			entry.Action = planActionCreate
+		case sync:
+			entry.Action = planActionSync
+		case recreate:
+			entry.Action = planActionRecreate
+		default:
+			entry.Action = planActionSkip
+		}
+
+		plan.Albums = append(plan.Albums, entry)
+	}
+
+	if withheld > 0 {
+		fmt.Printf("Leaving out %d photos taken inside %d privacy zones\n\n", withheld, privacy.zones)
+	}
+	return plan
+}
+
+// printAlbumPlan prints a human-readable plan grouped by action
+func printAlbumPlan(plan albumPlan) {
+	sections := []struct {
+		action string
+		title  string
+	}{
+		{planActionCreate, "Albums to create"},
+		{planActionRecreate, "Albums to delete and recreate"},
+		{planActionSync, "Albums to sync"},
+		{planActionSkip, "Albums to skip (already exist)"},
+		{planActionExclude, "Excluded trips"},
+	}
+
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Println("ALBUM PLAN (dry run, no changes made)")
+	fmt.Println(strings.Repeat("=", 60))
+
+	for _, section := range sections {
+		var entries []albumPlanEntry
+		for _, entry := range plan.Albums {
+			if entry.Action == section.action {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		fmt.Printf("\n%s: %d\n", section.title, len(entries))
+		for _, entry := range entries {
+			fmt.Printf("  - %s (%d photos)\n", entry.Name, entry.AssetCount)
+			if entry.AlbumID != "" {
+				fmt.Printf("      Album ID: %s\n", entry.AlbumID)
+			}
+			if entry.Action != planActionExclude && entry.Action != planActionSkip {
+				for _, line := range strings.Split(entry.Description, "\n") {
+					fmt.Printf("      %s\n", line)
+				}
+			}
+		}
+	}
+}
+
+// writeAlbumPlan saves a plan as indented JSON
+func writeAlbumPlan(plan albumPlan, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(plan)
+}
+
+// readAlbumPlan loads a plan written by writeAlbumPlan
+func readAlbumPlan(path string) (albumPlan, error) {
+	var plan albumPlan
+
+	file, err := os.Open(path)
+	if err != nil {
+		return plan, err
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(&plan); err != nil {
+		return plan, fmt.Errorf("failed to decode plan: %w", err)
+	}
+
+	for _, entry := range plan.Albums {
+		switch entry.Action {
+		case planActionCreate, planActionRecreate, planActionSync, planActionSkip, planActionExclude:
+		default:
+			return plan, fmt.Errorf("trip %d has unknown action %q", entry.TripID, entry.Action)
+		}
+	}
+
+	return plan, nil
+}
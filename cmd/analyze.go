@@ -48,6 +48,15 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get home locations: %w", err)
 	}
 
+	devices, err := db.GetDevices()
+	if err != nil {
+		return fmt.Errorf("failed to get devices: %w", err)
+	}
+	photographers := make(map[string]string)
+	for _, device := range devices {
+		photographers[device.ID] = device.Photographer
+	}
+
 	// Create sets for efficient lookups
 	assetsInSessions := make(map[string]bool)
 	for _, session := range sessions {
@@ -86,16 +95,7 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 		}
 
 		// Check if at home
-		atHome := false
-		if len(homes) > 0 {
-			for _, home := range homes {
-				distance := processor.CalculateDistance(lat, lon, home.Latitude, home.Longitude)
-				if distance <= home.Radius {
-					atHome = true
-					break
-				}
-			}
-		}
+		atHome := processor.IsAtHome(lat, lon, photographers[asset.DeviceKey], asset.LocalDateTime, homes)
 
 		if atHome {
 			photosAtHome++
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/models"
+	"github.com/jamo/immich-albums/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	clockMaxOffsetHours float64
+	clockToleranceMin   float64
+	clockSegmentGapDays float64
+	clockMinMatches     int
+	clockValidFrom      string
+	clockValidTo        string
+)
+
+var clockOffsetsCmd = &cobra.Command{
+	Use:   "clock-offsets",
+	Short: "Detect and correct camera clock offsets",
+	Long: `Cameras without GPS often have their clock set to the wrong time zone or drifted.
+Offsets are estimated per device by aligning its photos with GPS photos the same
+photographer took on a phone, and can change over date ranges.
+
+Suggested offsets are not used until accepted. Accepted and manual offsets are applied
+to photo times in infer-locations, detect-sessions and detect-trips. Immich is never modified.`,
+}
+
+var clockDetectCmd = &cobra.Command{
+	Use:   "detect",
+	Short: "Estimate clock offsets for labeled devices without GPS",
+	RunE:  runClockDetect,
+}
+
+var clockListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List clock offsets",
+	RunE:  runClockList,
+}
+
+var clockAcceptCmd = &cobra.Command{
+	Use:   "accept ID...",
+	Short: "Accept suggested clock offsets",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runClockAccept,
+}
+
+var clockSetCmd = &cobra.Command{
+	Use:   "set DEVICE OFFSET",
+	Short: "Set a manual clock offset for a device (e.g. +1h30m, -7h)",
+	Long: `Sets a manual clock offset, which overrides suggested and accepted offsets in its
+date range. OFFSET is added to the device's timestamps, so a camera that is 2 hours
+behind needs +2h. Without --from and --to the offset applies to all photos.
+Put negative offsets after "--" so they are not read as flags:
+
+  immich-albums clock-offsets set "canon-eos r6" -- -7h`,
+	Args: cobra.ExactArgs(2),
+	RunE: runClockSet,
+}
+
+var clockDeleteCmd = &cobra.Command{
+	Use:   "delete ID...",
+	Short: "Delete clock offsets",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runClockDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(clockOffsetsCmd)
+	clockOffsetsCmd.AddCommand(clockDetectCmd, clockListCmd, clockAcceptCmd, clockSetCmd, clockDeleteCmd)
+
+	defaults := processor.DefaultClockOffsetParams()
+	clockDetectCmd.Flags().Float64Var(&clockMaxOffsetHours, "max-offset", defaults.MaxOffset.Hours(), "Largest offset to search for in hours")
+	clockDetectCmd.Flags().Float64Var(&clockToleranceMin, "tolerance", defaults.Tolerance.Minutes(), "Minutes between a camera photo and a phone photo to count as aligned")
+	clockDetectCmd.Flags().Float64Var(&clockSegmentGapDays, "segment-gap", defaults.SegmentGap.Hours()/24, "Days without photos that start a new offset range")
+	clockDetectCmd.Flags().IntVar(&clockMinMatches, "min-matches", defaults.MinMatches, "Minimum aligned photos to suggest an offset")
+
+	clockSetCmd.Flags().StringVar(&clockValidFrom, "from", "", "First date the offset applies to (YYYY-MM-DD)")
+	clockSetCmd.Flags().StringVar(&clockValidTo, "to", "", "Last date the offset applies to (YYYY-MM-DD)")
+}
+
+func runClockDetect(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	fmt.Println("Loading assets and devices from database...")
+	assets, err := db.GetAssets()
+	if err != nil {
+		return fmt.Errorf("failed to get assets: %w", err)
+	}
+	devices, err := db.GetDevices()
+	if err != nil {
+		return fmt.Errorf("failed to get devices: %w", err)
+	}
+
+	params := processor.ClockOffsetParams{
+		MaxOffset:  time.Duration(clockMaxOffsetHours * float64(time.Hour)),
+		Tolerance:  time.Duration(clockToleranceMin * float64(time.Minute)),
+		SegmentGap: time.Duration(clockSegmentGapDays * 24 * float64(time.Hour)),
+		MinMatches: clockMinMatches,
+	}
+
+	fmt.Println("\nEstimating clock offsets...")
+	offsets := processor.EstimateClockOffsets(assets, devices, params)
+
+	if err := db.ReplaceSuggestedClockOffsets(offsets); err != nil {
+		return fmt.Errorf("failed to store clock offsets: %w", err)
+	}
+
+	if len(offsets) == 0 {
+		fmt.Println("No clock offsets found")
+		return nil
+	}
+
+	fmt.Printf("\n✓ Suggested %d clock offsets\n", len(offsets))
+	fmt.Println("Review them with 'clock-offsets list' and apply with 'clock-offsets accept ID'")
+	return nil
+}
+
+func runClockList(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	offsets, err := db.GetClockOffsets()
+	if err != nil {
+		return fmt.Errorf("failed to get clock offsets: %w", err)
+	}
+
+	if len(offsets) == 0 {
+		fmt.Println("No clock offsets. Run 'clock-offsets detect' first.")
+		return nil
+	}
+
+	for _, offset := range offsets {
+		fmt.Printf("[%d] %s: %s %s (%s", offset.ID, offset.DeviceID,
+			processor.FormatClockOffset(offset.OffsetSeconds), clockRange(offset), offset.Status)
+		if offset.Status != models.ClockOffsetManual {
+			fmt.Printf(", confidence %.2f, %d matches", offset.Confidence, offset.Matches)
+		}
+		fmt.Println(")")
+	}
+	return nil
+}
+
+func runClockAccept(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid clock offset ID %q", arg)
+		}
+		if err := db.UpdateClockOffsetStatus(id, models.ClockOffsetAccepted); err != nil {
+			return fmt.Errorf("failed to accept clock offset: %w", err)
+		}
+		fmt.Printf("✓ Accepted clock offset %d\n", id)
+	}
+
+	fmt.Println("\nRe-run infer-locations, detect-sessions and detect-trips to use the corrected times")
+	return nil
+}
+
+func runClockSet(cmd *cobra.Command, args []string) error {
+	offset, err := parseClockOffset(args[1])
+	if err != nil {
+		return err
+	}
+
+	clock := models.ClockOffset{
+		DeviceID:      args[0],
+		OffsetSeconds: int64(offset / time.Second),
+		Confidence:    1.0,
+		Status:        models.ClockOffsetManual,
+	}
+	if clockValidFrom != "" {
+		clock.ValidFrom, err = time.Parse("2006-01-02", clockValidFrom)
+		if err != nil {
+			return fmt.Errorf("invalid --from date: %w", err)
+		}
+	}
+	if clockValidTo != "" {
+		validTo, err := time.Parse("2006-01-02", clockValidTo)
+		if err != nil {
+			return fmt.Errorf("invalid --to date: %w", err)
+		}
+		// Include the whole last day
+		clock.ValidTo = validTo.Add(24*time.Hour - time.Second)
+	}
+
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	devices, err := db.GetDevices()
+	if err != nil {
+		return fmt.Errorf("failed to get devices: %w", err)
+	}
+	found := false
+	for _, device := range devices {
+		if device.ID == clock.DeviceID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("unknown device %q", clock.DeviceID)
+	}
+
+	id, err := db.StoreClockOffset(clock)
+	if err != nil {
+		return fmt.Errorf("failed to store clock offset: %w", err)
+	}
+
+	fmt.Printf("✓ Set clock offset %d: %s %s\n", id, processor.FormatClockOffset(clock.OffsetSeconds), clockRange(clock))
+	return nil
+}
+
+func runClockDelete(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid clock offset ID %q", arg)
+		}
+		if err := db.DeleteClockOffset(id); err != nil {
+			return fmt.Errorf("failed to delete clock offset: %w", err)
+		}
+		fmt.Printf("✓ Deleted clock offset %d\n", id)
+	}
+	return nil
+}
+
+// parseClockOffset parses a signed duration such as "+1h30m" or "-7h"
+func parseClockOffset(s string) (time.Duration, error) {
+	offset, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid offset %q (expected e.g. +1h30m or -7h): %w", s, err)
+	}
+	return offset, nil
+}
+
+// clockRange describes the date range of an offset
+func clockRange(offset models.ClockOffset) string {
+	return dateRange(offset.ValidFrom, offset.ValidTo)
+}
+
+// dateRange describes a date range where zero times mean no bound
+func dateRange(validFrom, validTo time.Time) string {
+	if validFrom.IsZero() && validTo.IsZero() {
+		return "always"
+	}
+	from, to := "start", "now"
+	if !validFrom.IsZero() {
+		from = validFrom.Format("2006-01-02")
+	}
+	if !validTo.IsZero() {
+		to = validTo.Format("2006-01-02")
+	}
+	return fmt.Sprintf("%s to %s", from, to)
+}
+
+// applyClockOffsets corrects asset times with the accepted and manual clock offsets
+func applyClockOffsets(db *database.DB, assets []models.Asset) ([]models.Asset, error) {
+	offsets, err := db.GetClockOffsets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clock offsets: %w", err)
+	}
+	if len(offsets) == 0 {
+		return assets, nil
+	}
+
+	devices, err := db.GetDevices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+
+	corrected, count := processor.ApplyClockOffsets(assets, devices, offsets)
+	if count > 0 {
+		fmt.Printf("Corrected clock offsets for %d assets\n", count)
+	}
+	return corrected, nil
+}
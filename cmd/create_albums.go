@@ -1,24 +1,40 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/i18n"
 	"github.com/jamo/immich-albums/internal/immich"
+	"github.com/jamo/immich-albums/internal/models"
+	"github.com/jamo/immich-albums/internal/processor"
 	"github.com/spf13/cobra"
 )
 
 var (
-	recreate bool
+	recreate   bool
+	syncAlbums bool
+	dryRun     bool
+	planFile   string
+	applyPlan  string
 )
 
 var createAlbumsCmd = &cobra.Command{
 	Use:   "create-albums",
 	Short: "Create albums in Immich from detected trips",
 	Long: `Creates albums in Immich for each detected trip.
-Albums are marked with their IDs so they can be regenerated if needed.`,
+Albums are marked with their IDs so they can be regenerated if needed.
+
+With --sync, existing albums are updated in place: missing trip photos are added,
+photos this tool added that are no longer part of the trip are removed, and the album
+name and description follow the trip. Photos added to the album by hand, album IDs,
+shared links and comments are kept.
+
+With --dry-run, the plan is printed and nothing is sent to Immich. Use --plan-file
+to save the plan as JSON, review it, and apply it verbatim with --apply.`,
 	RunE: runCreateAlbums,
 }
 
@@ -26,28 +42,87 @@ func init() {
 	rootCmd.AddCommand(createAlbumsCmd)
 
 	createAlbumsCmd.Flags().BoolVar(&recreate, "recreate", false, "Delete and recreate existing albums")
+	createAlbumsCmd.Flags().BoolVar(&syncAlbums, "sync", false, "Update existing albums to match their trips instead of skipping them")
+	createAlbumsCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the plan without making any changes in Immich")
+	createAlbumsCmd.Flags().StringVar(&planFile, "plan-file", "", "Write the plan as JSON to this file")
+	createAlbumsCmd.Flags().StringVar(&applyPlan, "apply", "", "Apply a plan file written with --plan-file instead of planning from the database")
+}
+
+// albumSyncResult records what a sync changed in one album
+type albumSyncResult struct {
+	Name    string
+	Added   int
+	Removed int
+	Kept    int // Photos added to the album by hand
+	Renamed bool
 }
 
 func runCreateAlbums(cmd *cobra.Command, args []string) error {
+	if recreate && syncAlbums {
+		return fmt.Errorf("--recreate and --sync cannot be used together")
+	}
+	if applyPlan != "" && (recreate || syncAlbums || dryRun || planFile != "") {
+		return fmt.Errorf("--apply cannot be combined with --recreate, --sync, --dry-run or --plan-file")
+	}
+
 	db, err := database.Open(dbPath)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
 	defer db.Close()
 
-	// Load trips
-	fmt.Println("Loading trips from database...")
-	trips, err := db.GetTrips()
+	privacy, err := loadAlbumPrivacy(db)
 	if err != nil {
-		return fmt.Errorf("failed to get trips: %w", err)
+		return err
 	}
 
-	if len(trips) == 0 {
-		fmt.Println("No trips found. Run 'detect-trips' first.")
-		return nil
+	var plan albumPlan
+	if applyPlan != "" {
+		fmt.Printf("Loading plan from %s...\n", applyPlan)
+		plan, err = readAlbumPlan(applyPlan)
+		if err != nil {
+			return fmt.Errorf("failed to read plan: %w", err)
+		}
+		// Privacy zones added after the plan was written make it unsafe to apply
+		if err := checkPlanPrivacy(plan, privacy); err != nil {
+			return err
+		}
+		fmt.Printf("Plan created %s with %d trips\n\n", plan.CreatedAt.Format("Jan 2, 2006 15:04"), len(plan.Albums))
+	} else {
+		// Load trips
+		fmt.Println("Loading trips from database...")
+		trips, err := db.GetTrips()
+		if err != nil {
+			return fmt.Errorf("failed to get trips: %w", err)
+		}
+
+		if len(trips) == 0 {
+			fmt.Println("No trips found. Run 'detect-trips' first.")
+			return nil
+		}
+
+		fmt.Printf("Found %d trips\n\n", len(trips))
+		catalog, err := loadCatalog()
+		if err != nil {
+			return err
+		}
+		plan = buildAlbumPlan(trips, recreate, syncAlbums, catalog, privacy)
+	}
+
+	if planFile != "" {
+		if err := writeAlbumPlan(plan, planFile); err != nil {
+			return fmt.Errorf("failed to write plan: %w", err)
+		}
+		fmt.Printf("✓ Plan written to %s\n\n", planFile)
 	}
 
-	fmt.Printf("Found %d trips\n\n", len(trips))
+	if dryRun {
+		printAlbumPlan(plan)
+		if planFile != "" {
+			fmt.Printf("\nReview the plan, then run 'create-albums --apply %s' to apply it\n", planFile)
+		}
+		return nil
+	}
 
 	// Create Immich client
 	client := immich.NewClient(immichURL, immichAPIKey)
@@ -56,57 +131,46 @@ func runCreateAlbums(cmd *cobra.Command, args []string) error {
 	updated := 0
 	skipped := 0
 	errors := 0
+	var synced []albumSyncResult
 
-	for i, trip := range trips {
-		fmt.Printf("[%d/%d] Processing: %s\n", i+1, len(trips), trip.Name)
-		fmt.Printf("        Photos: %d\n", len(trip.AssetIDs))
+	for i, entry := range plan.Albums {
+		fmt.Printf("[%d/%d] Processing: %s\n", i+1, len(plan.Albums), entry.Name)
+		fmt.Printf("        Photos: %d\n", len(entry.AssetIDs))
 
-		// Check if trip is excluded from album creation
-		if trip.ExcludeFromAlbum {
+		switch entry.Action {
+		case planActionExclude:
 			fmt.Println("        ⏭️  Trip excluded from album creation, skipping")
 			skipped++
 			continue
-		}
-
-		// Check if album already exists
-		if trip.AlbumID != "" {
-			if recreate {
-				fmt.Printf("        Deleting existing album (ID: %s)...\n", trip.AlbumID)
-				if err := client.DeleteAlbum(trip.AlbumID); err != nil {
-					fmt.Printf("        ⚠️  Warning: Failed to delete album: %v\n", err)
-					// Continue anyway - album might not exist anymore
-				}
-				trip.AlbumID = ""
-			} else {
-				fmt.Printf("        ⏭️  Album already exists (ID: %s), skipping\n", trip.AlbumID)
-				fmt.Println("        Use --recreate flag to delete and recreate albums")
-				skipped++
+		case planActionSkip:
+			fmt.Printf("        ⏭️  Album already exists (ID: %s), skipping\n", entry.AlbumID)
+			fmt.Println("        Use --sync to update it or --recreate to delete and recreate it")
+			skipped++
+			continue
+		case planActionSync:
+			result, err := syncAlbum(client, db, entry)
+			if err == nil {
+				synced = append(synced, result)
+				fmt.Printf("        ✓ Synced: +%d -%d, kept %d added by hand\n", result.Added, result.Removed, result.Kept)
 				continue
 			}
+			if !isNotFound(err) {
+				fmt.Printf("        ❌ Error syncing album: %v\n", err)
+				errors++
+				continue
+			}
+			fmt.Printf("        Album %s no longer exists in Immich, creating a new one\n", entry.AlbumID)
+		case planActionRecreate:
+			fmt.Printf("        Deleting existing album (ID: %s)...\n", entry.AlbumID)
+			if err := client.DeleteAlbum(entry.AlbumID); err != nil {
+				fmt.Printf("        ⚠️  Warning: Failed to delete album: %v\n", err)
+				// Continue anyway - album might not exist anymore
+			}
 		}
 
-		// Create album description
-		duration := trip.EndTime.Sub(trip.StartTime)
-		var durationStr string
-		if duration > 24*time.Hour {
-			durationStr = fmt.Sprintf("%.1f days", duration.Hours()/24)
-		} else {
-			durationStr = fmt.Sprintf("%.1f hours", duration.Hours())
-		}
-
-		description := fmt.Sprintf("%s - %s (%s)\n%d photos by %s\nDistance: %.0fkm from home, %.0fkm traveled",
-			trip.StartTime.Format("Jan 2, 2006"),
-			trip.EndTime.Format("Jan 2, 2006"),
-			durationStr,
-			len(trip.AssetIDs),
-			trip.Photographers,
-			trip.HomeDistance,
-			trip.TotalDistance,
-		)
-
 		// Create album
 		fmt.Println("        Creating album in Immich...")
-		albumID, err := client.CreateAlbum(trip.Name, description)
+		albumID, err := client.CreateAlbum(entry.Name, entry.Description)
 		if err != nil {
 			fmt.Printf("        ❌ Error creating album: %v\n", err)
 			errors++
@@ -116,22 +180,36 @@ func runCreateAlbums(cmd *cobra.Command, args []string) error {
 		fmt.Printf("        Album created with ID: %s\n", albumID)
 
 		// Add assets to album
-		if len(trip.AssetIDs) > 0 {
-			fmt.Printf("        Adding %d photos to album...\n", len(trip.AssetIDs))
-			if err := client.AddAssetsToAlbum(albumID, trip.AssetIDs); err != nil {
+		added := true
+		if len(entry.AssetIDs) > 0 {
+			fmt.Printf("        Adding %d photos to album...\n", len(entry.AssetIDs))
+			if err := client.AddAssetsToAlbum(albumID, entry.AssetIDs); err != nil {
 				fmt.Printf("        ⚠️  Warning: Failed to add assets: %v\n", err)
 				// Album was created, so still update the ID
+				added = false
+			}
+		}
+
+		// Remember what was added so --sync never removes photos added by hand
+		if entry.AlbumID != "" {
+			if err := db.DeleteAlbumAssets(entry.AlbumID); err != nil {
+				fmt.Printf("        ⚠️  Warning: Failed to clear album journal: %v\n", err)
+			}
+		}
+		if added {
+			if err := db.StoreAlbumAssets(albumID, entry.AssetIDs); err != nil {
+				fmt.Printf("        ⚠️  Warning: Failed to save album journal: %v\n", err)
 			}
 		}
 
 		// Update trip with album ID
-		if err := db.UpdateTripAlbumID(trip.ID, albumID); err != nil {
+		if err := db.UpdateTripAlbumID(entry.TripID, albumID); err != nil {
 			fmt.Printf("        ⚠️  Warning: Failed to save album ID: %v\n", err)
 		}
 
 		fmt.Println("        ✓ Complete!")
 
-		if trip.AlbumID != "" && recreate {
+		if entry.Action == planActionRecreate {
 			updated++
 		} else {
 			created++
@@ -142,11 +220,21 @@ func runCreateAlbums(cmd *cobra.Command, args []string) error {
 	fmt.Println("\n" + strings.Repeat("=", 60))
 	fmt.Println("ALBUM CREATION SUMMARY")
 	fmt.Println(strings.Repeat("=", 60))
-	fmt.Printf("Total trips: %d\n", len(trips))
+	fmt.Printf("Total trips: %d\n", len(plan.Albums))
 	fmt.Printf("  Albums created: %d\n", created)
 	if updated > 0 {
 		fmt.Printf("  Albums recreated: %d\n", updated)
 	}
+	if len(synced) > 0 {
+		fmt.Printf("  Albums synced: %d\n", len(synced))
+		for _, result := range synced {
+			renamed := ""
+			if result.Renamed {
+				renamed = " (renamed)"
+			}
+			fmt.Printf("    - %s: +%d -%d, kept %d added by hand%s\n", result.Name, result.Added, result.Removed, result.Kept, renamed)
+		}
+	}
 	if skipped > 0 {
 		fmt.Printf("  Albums skipped: %d\n", skipped)
 	}
@@ -158,3 +246,247 @@ func runCreateAlbums(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// albumPrivacy leaves photos located inside privacy zones out of album plans
+type albumPrivacy struct {
+	zones      int
+	private    map[string]bool
+	assetMap   map[string]models.Asset
+	inferences map[string]models.LocationInference
+	devices    map[string]models.Device
+	homes      []models.HomeLocation
+}
+
+// loadAlbumPrivacy loads the privacy zones and what is needed to summarize trips without
+// the photos inside them. It returns nil when there are no privacy zones.
+func loadAlbumPrivacy(db *database.DB) (*albumPrivacy, error) {
+	zones, err := db.GetZones(models.ZonePrivacy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zones: %w", err)
+	}
+	if len(zones) == 0 {
+		return nil, nil
+	}
+
+	assets, err := db.GetAssets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assets: %w", err)
+	}
+	inferences, err := db.GetInferredLocations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inferred locations: %w", err)
+	}
+	devices, err := db.GetDevices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+	homes, err := db.GetHomeLocations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home locations: %w", err)
+	}
+
+	p := &albumPrivacy{
+		zones:      len(zones),
+		private:    processor.PrivateAssets(assets, inferences, zones),
+		assetMap:   make(map[string]models.Asset, len(assets)),
+		inferences: inferences,
+		devices:    make(map[string]models.Device, len(devices)),
+		homes:      homes,
+	}
+	for _, asset := range assets {
+		p.assetMap[asset.ID] = asset
+	}
+	for _, d := range devices {
+		p.devices[d.ID] = d
+	}
+	return p, nil
+}
+
+// withhold returns the trip without its private photos and the number left out. Trips that
+// lose photos get a summary of the remaining ones, so their album description neither
+// counts the private photos nor names their places.
+func (p *albumPrivacy) withhold(trip models.Trip) (models.Trip, int) {
+	if p == nil {
+		return trip, 0
+	}
+	kept := processor.WithoutAssets(trip.AssetIDs, p.private)
+	withheld := len(trip.AssetIDs) - len(kept)
+	if withheld == 0 {
+		return trip, 0
+	}
+	trip.AssetIDs = kept
+	trip.Summary = processor.SummarizeTrip(trip, p.assetMap, p.inferences, p.devices, p.homes, p.private)
+	return trip, withheld
+}
+
+// checkPlanPrivacy refuses a plan that would add photos now inside a privacy zone. Plans are
+// applied verbatim, so such a plan has to be written again.
+func checkPlanPrivacy(plan albumPlan, p *albumPrivacy) error {
+	if p == nil {
+		return nil
+	}
+	count := 0
+	for _, entry := range plan.Albums {
+		if entry.Action == planActionExclude || entry.Action == planActionSkip {
+			continue
+		}
+		count += len(entry.AssetIDs) - len(processor.WithoutAssets(entry.AssetIDs, p.private))
+	}
+	if count > 0 {
+		return fmt.Errorf("the plan includes %d photos that are now inside privacy zones; write a new plan with 'create-albums --dry-run --plan-file'", count)
+	}
+	return nil
+}
+
+// albumDescription builds the Immich album description for a trip
+func albumDescription(trip models.Trip, catalog *i18n.Catalog) string {
+	duration := trip.EndTime.Sub(trip.StartTime)
+	var durationStr string
+	if duration > 24*time.Hour {
+		durationStr = catalog.T("duration_days", "days", catalog.Number(duration.Hours()/24, 1))
+	} else {
+		durationStr = catalog.T("duration_hours", "hours", catalog.Number(duration.Hours(), 1))
+	}
+
+	lines := []string{
+		catalog.T("album_dates",
+			"start", catalog.FormatDate("date", trip.StartTime, trip.StartTime),
+			"end", catalog.FormatDate("date", trip.EndTime, trip.EndTime),
+			"duration", durationStr),
+		catalog.T("album_photos", "count", len(trip.AssetIDs), "photographers", trip.Photographers),
+		catalog.T("album_distance", "home", catalog.Number(trip.HomeDistance, 0), "traveled", catalog.Number(trip.TotalDistance, 0)),
+	}
+
+	summary := trip.Summary
+	if summary.FarthestDistance > 0 {
+		lines[len(lines)-1] += catalog.T("album_farthest", "km", catalog.Number(summary.FarthestDistance, 0))
+	}
+	if summary.NightsAway > 0 {
+		lines = append(lines, catalog.T("album_nights", "nights", summary.NightsAway))
+	}
+	if len(summary.Countries) > 0 {
+		lines = append(lines, catalog.T("album_countries", "countries", strings.Join(summary.Countries, ", ")))
+	}
+	if len(summary.Cities) > 0 {
+		lines = append(lines, catalog.T("album_route", "route", processor.RouteString(summary.Cities)))
+	}
+	for _, flight := range summary.Flights {
+		lines = append(lines, catalog.T("album_flight",
+			"date", catalog.FormatDate("day", flight.Departure, flight.Departure),
+			"route", processor.RouteString([]string{placeOrUnknown(flight.From), placeOrUnknown(flight.To)}),
+			"km", catalog.Number(flight.DistanceKM, 0)))
+	}
+	if stays := itineraryLines(summary.Days, catalog); len(stays) > 1 {
+		lines = append(lines, catalog.T("album_itinerary"))
+		lines = append(lines, stays...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// itineraryLines lists where a trip was, one line per stay of consecutive days in a city
+func itineraryLines(days []models.TripDay, catalog *i18n.Catalog) []string {
+	var lines []string
+	for i := 0; i < len(days); {
+		if days[i].City == "" {
+			i++
+			continue
+		}
+		j := i
+		for j+1 < len(days) && days[j+1].City == days[i].City {
+			j++
+		}
+		first, _ := time.Parse("2006-01-02", days[i].Date)
+		last, _ := time.Parse("2006-01-02", days[j].Date)
+		dates := catalog.FormatDate("day", first, first)
+		if j > i {
+			dates = catalog.FormatDate("day_range", first, last)
+		}
+		lines = append(lines, catalog.T("album_itinerary_stay", "dates", dates, "city", days[i].City))
+		i = j + 1
+	}
+	return lines
+}
+
+// syncAlbum brings an existing album in line with its planned contents: it adds missing
+// assets, removes assets it added earlier that left the trip and updates the name and
+// description if they changed. Assets added to the album by hand are kept.
+func syncAlbum(client *immich.Client, db *database.DB, entry albumPlanEntry) (albumSyncResult, error) {
+	result := albumSyncResult{Name: entry.Name}
+
+	fmt.Printf("        Fetching album (ID: %s)...\n", entry.AlbumID)
+	album, err := client.GetAlbum(entry.AlbumID)
+	if err != nil {
+		return result, err
+	}
+
+	// Only assets the tool added can be removed. Albums created before the journal have
+	// none, so nothing is removed from them until they have been synced once.
+	journal, err := db.GetAlbumAssets(entry.AlbumID)
+	if err != nil {
+		return result, fmt.Errorf("failed to get album journal: %w", err)
+	}
+	managed := make(map[string]bool, len(journal))
+	for _, id := range journal {
+		managed[id] = true
+	}
+
+	inAlbum := make(map[string]bool, len(album.AssetIDs))
+	for _, id := range album.AssetIDs {
+		inAlbum[id] = true
+	}
+	inTrip := make(map[string]bool, len(entry.AssetIDs))
+	for _, id := range entry.AssetIDs {
+		inTrip[id] = true
+	}
+
+	var toAdd, toRemove []string
+	for _, id := range entry.AssetIDs {
+		if !inAlbum[id] {
+			toAdd = append(toAdd, id)
+		}
+	}
+	for _, id := range album.AssetIDs {
+		switch {
+		case inTrip[id]:
+		case managed[id]:
+			toRemove = append(toRemove, id)
+		default:
+			result.Kept++
+		}
+	}
+
+	if album.Name != entry.Name || album.Description != entry.Description {
+		fmt.Println("        Updating album name and description...")
+		if err := client.UpdateAlbum(entry.AlbumID, entry.Name, entry.Description); err != nil {
+			return result, err
+		}
+		result.Renamed = album.Name != entry.Name
+	}
+
+	if len(toAdd) > 0 {
+		fmt.Printf("        Adding %d photos to album...\n", len(toAdd))
+		if err := client.AddAssetsToAlbum(entry.AlbumID, toAdd); err != nil {
+			return result, err
+		}
+		result.Added = len(toAdd)
+	}
+
+	if len(toRemove) > 0 {
+		fmt.Printf("        Removing %d photos from album...\n", len(toRemove))
+		if err := client.RemoveAssetsFromAlbum(entry.AlbumID, toRemove); err != nil {
+			return result, err
+		}
+		result.Removed = len(toRemove)
+	}
+
+	if err := db.StoreAlbumAssets(entry.AlbumID, entry.AssetIDs); err != nil {
+		return result, fmt.Errorf("failed to save album journal: %w", err)
+	}
+
+	return result, nil
+}
+
+// isNotFound reports whether err means the Immich resource no longer exists
+func isNotFound(err error) bool {
+	return errors.Is(err, immich.ErrNotFound)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamo/immich-albums/internal/database"
+	"github.com/spf13/cobra"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the local database schema",
+	Long: `Every command migrates the database to the latest schema when it opens it.
+Use these commands to check the schema version or to migrate explicitly, for example
+after taking a backup.`,
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations",
+	RunE:  runDBMigrate,
+}
+
+var dbStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the schema version and pending migrations",
+	RunE:  runDBStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbMigrateCmd, dbStatusCmd)
+}
+
+func runDBMigrate(cmd *cobra.Command, args []string) error {
+	db, err := database.OpenUnmigrated(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	applied, err := db.Migrate()
+	for _, m := range applied {
+		fmt.Printf("✓ Applied migration %d: %s\n", m.Version, m.Description)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		fmt.Printf("Database is up to date (version %d)\n", database.LatestSchemaVersion())
+		return nil
+	}
+	fmt.Printf("\n✓ Database migrated to version %d\n", database.LatestSchemaVersion())
+	return nil
+}
+
+func runDBStatus(cmd *cobra.Command, args []string) error {
+	db, err := database.OpenUnmigrated(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	statuses, err := db.SchemaStatus()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Schema version: %d (latest %d)\n\n", current, database.LatestSchemaVersion())
+	pending := 0
+	for _, m := range statuses {
+		if m.AppliedAt.IsZero() {
+			pending++
+			fmt.Printf("  ⏭️  %3d  %s (pending)\n", m.Version, m.Description)
+			continue
+		}
+		fmt.Printf("  ✓ %3d  %s (applied %s)\n", m.Version, m.Description, m.AppliedAt.Local().Format("2006-01-02 15:04"))
+	}
+
+	if pending > 0 {
+		fmt.Printf("\n%d pending migrations. Run 'db migrate' to apply them\n", pending)
+	}
+	return nil
+}
@@ -14,13 +14,22 @@ import (
 var (
 	startDate string
 	endDate   string
+	syncMode  bool
+	reconcile bool
 )
 
 var discoverCmd = &cobra.Command{
 	Use:   "discover",
 	Short: "Discover devices and fetch photos from Immich",
 	Long: `Fetches photos from Immich for the specified date range,
-discovers all unique camera and phone models, and stores metadata locally.`,
+discovers all unique camera and phone models, and stores metadata locally.
+
+With --sync, only assets created or modified in Immich since the previous
+sync of the same date range are fetched. The first sync does a full import.
+
+Assets that were trashed, archived or deleted in Immich are removed locally,
+and the sessions and trips containing them are updated. A sync only sees
+trashed and archived assets; add --reconcile to also detect permanent deletions.`,
 	RunE: runDiscover,
 }
 
@@ -29,21 +38,30 @@ func init() {
 
 	discoverCmd.Flags().StringVar(&startDate, "start-date", "", "Start date (YYYY-MM-DD)")
 	discoverCmd.Flags().StringVar(&endDate, "end-date", "", "End date (YYYY-MM-DD)")
-
-	discoverCmd.MarkFlagRequired("start-date")
-	discoverCmd.MarkFlagRequired("end-date")
+	discoverCmd.Flags().BoolVar(&syncMode, "sync", false, "Only fetch assets added or changed since the last sync")
+	discoverCmd.Flags().BoolVar(&reconcile, "reconcile", false, "With --sync, fetch the full asset list to detect assets deleted from Immich")
 }
 
 func runDiscover(cmd *cobra.Command, args []string) error {
+	if !syncMode && (startDate == "" || endDate == "") {
+		return fmt.Errorf("--start-date and --end-date are required (or use --sync)")
+	}
+
 	// Parse dates
-	start, err := time.Parse("2006-01-02", startDate)
-	if err != nil {
-		return fmt.Errorf("invalid start date: %w", err)
+	var start, end time.Time
+	var err error
+	if startDate != "" {
+		start, err = time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return fmt.Errorf("invalid start date: %w", err)
+		}
 	}
 
-	end, err := time.Parse("2006-01-02", endDate)
-	if err != nil {
-		return fmt.Errorf("invalid end date: %w", err)
+	if endDate != "" {
+		end, err = time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return fmt.Errorf("invalid end date: %w", err)
+		}
 	}
 
 	// Initialize database
@@ -57,19 +75,72 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 	client := immich.NewClient(immichURL, immichAPIKey)
 
 	// Fetch assets
-	fmt.Printf("Fetching assets from %s to %s...\n", startDate, endDate)
-	assets, err := client.FetchAssets(start, end)
+	var assets []models.Asset
+	var syncState *database.SyncState
+	syncScope := "all"
+	if startDate != "" || endDate != "" {
+		syncScope = fmt.Sprintf("%s..%s", startDate, endDate)
+	}
+	if syncMode {
+		syncState, err = db.GetSyncState(syncScope)
+		if err != nil {
+			return fmt.Errorf("failed to get sync state: %w", err)
+		}
+
+		var updatedAfter time.Time
+		if syncState != nil && !reconcile {
+			updatedAfter = syncState.LastUpdatedAt
+			fmt.Printf("Syncing assets updated since %s...\n", updatedAfter.Format(time.RFC3339))
+		} else if reconcile {
+			fmt.Println("Reconciling, fetching all assets...")
+		} else {
+			fmt.Println("No previous sync found, fetching all assets...")
+		}
+
+		assets, err = client.FetchAssetsUpdatedAfter(updatedAfter, start, end)
+	} else {
+		fmt.Printf("Fetching assets from %s to %s...\n", startDate, endDate)
+		assets, err = client.FetchAssets(start, end)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to fetch assets: %w", err)
 	}
 
 	fmt.Printf("Fetched %d assets\n", len(assets))
 
+	// Coordinates written by push-locations are still inferences, not EXIF GPS
+	journal, err := db.GetLocationPushes()
+	if err != nil {
+		return fmt.Errorf("failed to get location journal: %w", err)
+	}
+	if stripped := stripPushedLocations(assets, journal); stripped > 0 {
+		fmt.Printf("Ignoring locations pushed by push-locations on %d assets\n", stripped)
+	}
+
+	// Find local assets that were trashed, archived or deleted in Immich.
+	// Only a listing without an updatedAfter filter is complete enough to spot deletions.
+	localAssets, err := db.GetAssets()
+	if err != nil {
+		return fmt.Errorf("failed to get assets: %w", err)
+	}
+	completeListing := !syncMode || reconcile || syncState == nil
+	if len(assets) == 0 {
+		// An empty listing is more likely a permissions problem than an empty library
+		completeListing = false
+	}
+	removedIDs := processor.FindRemovedAssets(localAssets, assets, start, end, completeListing)
+
 	// Validate timestamps and filter out invalid assets
 	fmt.Println("Validating asset timestamps...")
 	validAssets := make([]models.Asset, 0, len(assets))
 	invalidCount := 0
+	hiddenCount := 0
 	for _, asset := range assets {
+		// Trashed and archived assets never belong in sessions or albums
+		if asset.IsTrashed || asset.IsArchived {
+			hiddenCount++
+			continue
+		}
 		// Check for zero/invalid timestamp
 		if asset.LocalDateTime.IsZero() {
 			invalidCount++
@@ -87,13 +158,51 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 	if invalidCount > 0 {
 		fmt.Printf("Warning: Skipped %d assets with invalid timestamps\n", invalidCount)
 	}
+	if hiddenCount > 0 {
+		fmt.Printf("Skipped %d trashed or archived assets\n", hiddenCount)
+	}
 	fmt.Printf("Valid assets: %d\n", len(validAssets))
 
 	// Store assets in database
 	fmt.Println("Storing assets in database...")
-	if err := db.StoreAssets(validAssets); err != nil {
+	stats, err := db.StoreAssets(validAssets)
+	if err != nil {
 		return fmt.Errorf("failed to store assets: %w", err)
 	}
+	fmt.Printf("  Added: %d\n", stats.Added)
+	fmt.Printf("  Changed: %d\n", stats.Changed)
+	fmt.Printf("  Unchanged: %d\n", stats.Unchanged)
+
+	if err := reconcileRemovedAssets(db, removedIDs); err != nil {
+		return fmt.Errorf("failed to reconcile removed assets: %w", err)
+	}
+
+	if syncMode {
+		// Advance the high-water mark to the newest modification seen
+		state := database.SyncState{Scope: syncScope, LastSyncedAt: time.Now()}
+		if syncState != nil {
+			state.LastUpdatedAt = syncState.LastUpdatedAt
+		}
+		for _, asset := range assets {
+			if asset.UpdatedAt.After(state.LastUpdatedAt) {
+				state.LastUpdatedAt = asset.UpdatedAt
+			}
+		}
+		if err := db.StoreSyncState(state); err != nil {
+			return fmt.Errorf("failed to store sync state: %w", err)
+		}
+
+		if stats.Added == 0 && stats.Changed == 0 && len(removedIDs) == 0 {
+			fmt.Println("\nNo new or changed assets, skipping device discovery")
+			return nil
+		}
+
+		// Device clustering needs the whole library, not just this batch
+		validAssets, err = db.GetAssets()
+		if err != nil {
+			return fmt.Errorf("failed to get assets: %w", err)
+		}
+	}
 
 	// Discover devices
 	fmt.Println("\nDiscovering devices...")
@@ -110,6 +219,12 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to store devices: %w", err)
 	}
 
+	// Resolve each asset's device once so later commands don't have to
+	fmt.Println()
+	if err := assignDeviceKeys(db); err != nil {
+		return err
+	}
+
 	fmt.Println("\nRun 'immich-albums label-devices' to assign photographers to devices")
 
 	return nil
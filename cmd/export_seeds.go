@@ -11,8 +11,8 @@ import (
 
 var exportSeedsCmd = &cobra.Command{
 	Use:   "export-seeds",
-	Short: "Export home locations and device labels to seed files",
-	Long:  `Exports your home locations and device photographer labels to JSON files for backup and restoration.`,
+	Short: "Export home locations, device labels, trip rules and zones to seed files",
+	Long:  `Exports your home locations, device photographer labels, manual trip rules and zones to JSON files for backup and restoration.`,
 	RunE:  runExportSeeds,
 }
 
@@ -93,6 +93,46 @@ func runExportSeeds(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("✓ Exported %d device labels to seeds/device_labels.json\n", len(labeledDevices))
+
+	// Export trip rules
+	rules, err := db.GetTripRules()
+	if err != nil {
+		return fmt.Errorf("failed to get trip rules: %w", err)
+	}
+
+	rulesFile, err := os.Create("seeds/trip_rules.json")
+	if err != nil {
+		return fmt.Errorf("failed to create trip_rules.json: %w", err)
+	}
+	defer rulesFile.Close()
+
+	encoder = json.NewEncoder(rulesFile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(rules); err != nil {
+		return fmt.Errorf("failed to encode trip rules: %w", err)
+	}
+
+	fmt.Printf("✓ Exported %d trip rules to seeds/trip_rules.json\n", len(rules))
+
+	// Export zones
+	zones, err := db.GetZones("")
+	if err != nil {
+		return fmt.Errorf("failed to get zones: %w", err)
+	}
+
+	zonesFile, err := os.Create("seeds/zones.json")
+	if err != nil {
+		return fmt.Errorf("failed to create zones.json: %w", err)
+	}
+	defer zonesFile.Close()
+
+	encoder = json.NewEncoder(zonesFile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(zones); err != nil {
+		return fmt.Errorf("failed to encode zones: %w", err)
+	}
+
+	fmt.Printf("✓ Exported %d zones to seeds/zones.json\n", len(zones))
 	fmt.Println("\nSeed files created successfully in seeds/ directory")
 
 	return nil
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/geojson"
+	"github.com/jamo/immich-albums/internal/models"
+	"github.com/jamo/immich-albums/internal/processor"
+	"github.com/jamo/immich-albums/internal/tracks"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat   string
+	exportOutput   string
+	exportPrivacy  string
+	exportSnapGrid float64
+)
+
+var exportTripCmd = &cobra.Command{
+	Use:   "export-trip TRIP_ID",
+	Short: "Export a trip's photo locations as GPX or GeoJSON",
+	Long: `Exports the original GPS locations of a trip's photos, in time order, as a GPX track
+or a GeoJSON feature collection.
+
+Locations inside privacy zones are removed, or with --privacy snap moved to the center of
+a coarse grid cell so only the rough area is revealed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExportTrip,
+}
+
+func init() {
+	rootCmd.AddCommand(exportTripCmd)
+
+	exportTripCmd.Flags().StringVar(&exportFormat, "format", "gpx", "Output format: gpx or geojson")
+	exportTripCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file (default: trip-ID.gpx or trip-ID.geojson)")
+	exportTripCmd.Flags().StringVar(&exportPrivacy, "privacy", processor.PrivacyRemove, "Locations inside privacy zones: remove or snap")
+	exportTripCmd.Flags().Float64Var(&exportSnapGrid, "snap-grid", 5.0, "Grid cell size in km for --privacy snap")
+}
+
+func runExportTrip(cmd *cobra.Command, args []string) error {
+	if exportFormat != "gpx" && exportFormat != "geojson" {
+		return fmt.Errorf("invalid format %q (expected gpx or geojson)", exportFormat)
+	}
+	if exportPrivacy != processor.PrivacyRemove && exportPrivacy != processor.PrivacySnap {
+		return fmt.Errorf("invalid privacy mode %q (expected %q or %q)", exportPrivacy, processor.PrivacyRemove, processor.PrivacySnap)
+	}
+
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	trip, err := getTripArg(db, args[0])
+	if err != nil {
+		return err
+	}
+
+	assets, err := db.GetAssets()
+	if err != nil {
+		return fmt.Errorf("failed to get assets: %w", err)
+	}
+	assets, err = applyClockOffsets(db, assets)
+	if err != nil {
+		return err
+	}
+	devices, err := db.GetDevices()
+	if err != nil {
+		return fmt.Errorf("failed to get devices: %w", err)
+	}
+	zones, err := db.GetZones(models.ZonePrivacy)
+	if err != nil {
+		return fmt.Errorf("failed to get zones: %w", err)
+	}
+
+	photographers := make(map[string]string)
+	for _, device := range devices {
+		photographers[device.ID] = device.Photographer
+	}
+	inTrip := make(map[string]bool)
+	for _, id := range trip.AssetIDs {
+		inTrip[id] = true
+	}
+
+	var points []models.TrackPoint
+	for _, asset := range assets {
+		if !inTrip[asset.ID] || asset.Latitude == nil || asset.Longitude == nil {
+			continue
+		}
+		points = append(points, models.TrackPoint{
+			Photographer: photographers[asset.DeviceKey],
+			Time:         asset.LocalDateTime,
+			Latitude:     *asset.Latitude,
+			Longitude:    *asset.Longitude,
+		})
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Time.Before(points[j].Time)
+	})
+
+	total := len(points)
+	points = processor.ApplyPrivacy(points, zones, exportPrivacy, exportSnapGrid)
+
+	path := exportOutput
+	if path == "" {
+		path = fmt.Sprintf("trip-%d.%s", trip.ID, exportFormat)
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer out.Close()
+
+	if exportFormat == "gpx" {
+		err = tracks.WriteGPX(out, trip.Name, points)
+	} else {
+		err = geojson.WriteTrack(out, trip.Name, points)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", exportFormat, err)
+	}
+
+	fmt.Printf("✓ Exported %d locations of %s to %s\n", len(points), trip.Name, path)
+	if exportPrivacy == processor.PrivacyRemove && len(points) < total {
+		fmt.Printf("  %d locations inside privacy zones removed\n", total-len(points))
+	}
+	return nil
+}
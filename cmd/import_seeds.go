@@ -12,8 +12,8 @@ import (
 
 var importSeedsCmd = &cobra.Command{
 	Use:   "import-seeds",
-	Short: "Import home locations and device labels from seed files",
-	Long:  `Imports home locations and device photographer labels from JSON seed files.`,
+	Short: "Import home locations, device labels, trip rules and zones from seed files",
+	Long:  `Imports home locations, device photographer labels, manual trip rules and zones from JSON seed files.`,
 	RunE:  runImportSeeds,
 }
 
@@ -82,6 +82,40 @@ func runImportSeeds(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("✓ Imported %d device labels\n", len(deviceLabels))
+
+	// Import trip rules (optional, older seed directories don't have them)
+	rulesFile, err := os.Open("seeds/trip_rules.json")
+	if err == nil {
+		defer rulesFile.Close()
+
+		var rules []models.TripRule
+		if err := json.NewDecoder(rulesFile).Decode(&rules); err != nil {
+			return fmt.Errorf("failed to decode trip rules: %w", err)
+		}
+		if err := db.ReplaceTripRules(rules); err != nil {
+			return fmt.Errorf("failed to store trip rules: %w", err)
+		}
+		fmt.Printf("✓ Imported %d trip rules\n", len(rules))
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to open seeds/trip_rules.json: %w", err)
+	}
+
+	// Import zones (optional, older seed directories don't have them)
+	zonesFile, err := os.Open("seeds/zones.json")
+	if err == nil {
+		defer zonesFile.Close()
+
+		var zones []models.Zone
+		if err := json.NewDecoder(zonesFile).Decode(&zones); err != nil {
+			return fmt.Errorf("failed to decode zones: %w", err)
+		}
+		if err := db.ReplaceZones(zones); err != nil {
+			return fmt.Errorf("failed to store zones: %w", err)
+		}
+		fmt.Printf("✓ Imported %d zones\n", len(zones))
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to open seeds/zones.json: %w", err)
+	}
 	fmt.Println("\nSeed files imported successfully!")
 
 	return nil
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/tracks"
+	"github.com/spf13/cobra"
+)
+
+var (
+	trackPhotographer string
+	trackTimezone     string
+	replaceTracks     bool
+)
+
+var importTracksCmd = &cobra.Command{
+	Use:   "import-tracks FILE...",
+	Short: "Import GPS tracks (GPX, KML, Google Timeline JSON) for a photographer",
+	Long: `Imports location tracks from watches, GPS loggers and Google Location History
+and stores them for a photographer. infer-locations uses them to place camera photos
+along the track, ahead of nearby phone photos.
+
+Supported formats:
+  .gpx   GPX tracks and waypoints
+  .kml   KML gx:Track and timestamped placemarks (Google Location History KML)
+  .json  Google Timeline: Takeout Records.json, Semantic Location History,
+         and the on-device Timeline export from Android or iOS
+
+Photo times are local wall-clock times. Track times given in UTC are converted using
+--timezone; times that carry their own UTC offset are used as-is.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runImportTracks,
+}
+
+func init() {
+	rootCmd.AddCommand(importTracksCmd)
+
+	importTracksCmd.Flags().StringVar(&trackPhotographer, "photographer", "", "Photographer the tracks belong to (required)")
+	importTracksCmd.Flags().StringVar(&trackTimezone, "timezone", "Local", "Time zone the photos were taken in, for tracks recorded in UTC (e.g. Europe/Helsinki)")
+	importTracksCmd.Flags().BoolVar(&replaceTracks, "replace", false, "Delete the photographer's existing track points first")
+	importTracksCmd.MarkFlagRequired("photographer")
+}
+
+func runImportTracks(cmd *cobra.Command, args []string) error {
+	loc, err := time.LoadLocation(trackTimezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	// Tracks are matched to photos through device labels
+	devices, err := db.GetDevices()
+	if err != nil {
+		return fmt.Errorf("failed to get devices: %w", err)
+	}
+	labeled := false
+	for _, device := range devices {
+		if device.Photographer == trackPhotographer {
+			labeled = true
+			break
+		}
+	}
+	if !labeled {
+		fmt.Printf("⚠️  Warning: No devices are labeled with photographer '%s'; the tracks won't be used until one is\n\n", trackPhotographer)
+	}
+
+	if replaceTracks {
+		deleted, err := db.DeleteTrackPoints(trackPhotographer)
+		if err != nil {
+			return fmt.Errorf("failed to delete track points: %w", err)
+		}
+		fmt.Printf("Deleted %d existing track points for %s\n\n", deleted, trackPhotographer)
+	}
+
+	totalAdded := 0
+	for _, path := range args {
+		fmt.Printf("Reading %s...\n", path)
+		points, err := tracks.ParseFile(path, loc)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if len(points) == 0 {
+			fmt.Println("  ⚠️  Warning: No timestamped points found")
+			continue
+		}
+
+		for i := range points {
+			points[i].Photographer = trackPhotographer
+		}
+
+		added, err := db.StoreTrackPoints(points)
+		if err != nil {
+			return fmt.Errorf("failed to store track points: %w", err)
+		}
+		totalAdded += added
+
+		fmt.Printf("  %d points from %s to %s\n", len(points),
+			points[0].Time.Format("2006-01-02 15:04"), points[len(points)-1].Time.Format("2006-01-02 15:04"))
+		fmt.Printf("  Added: %d, already imported: %d\n", added, len(points)-added)
+	}
+
+	fmt.Printf("\n✓ Imported %d track points for %s\n", totalAdded, trackPhotographer)
+	fmt.Println("Next: Run 'infer-locations' to place photos along the tracks")
+
+	return nil
+}
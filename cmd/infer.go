@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/models"
 	"github.com/jamo/immich-albums/internal/processor"
 	"github.com/spf13/cobra"
 )
@@ -16,8 +17,8 @@ var inferCmd = &cobra.Command{
 	Use:   "infer-locations",
 	Short: "Infer locations for photos without GPS data",
 	Long: `Analyzes photos and infers locations for DSLR images without GPS
-by using nearby phone photos from the same photographer. Handles gaps of days
-between photos with confidence scoring.`,
+by using tracks imported with 'import-tracks' and nearby phone photos from the
+same photographer. Handles gaps of days between photos with confidence scoring.`,
 	RunE: runInfer,
 }
 
@@ -41,6 +42,10 @@ func runInfer(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get assets: %w", err)
 	}
 	fmt.Printf("Loaded %d assets\n", len(assets))
+	assets, err = applyClockOffsets(db, assets)
+	if err != nil {
+		return err
+	}
 
 	// Load devices
 	fmt.Println("Loading device labels...")
@@ -63,9 +68,15 @@ func runInfer(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Found %d labeled devices out of %d total\n", labeledCount, len(devices))
 
+	// Load imported tracks
+	tracks, err := db.GetTrackPoints()
+	if err != nil {
+		return fmt.Errorf("failed to get track points: %w", err)
+	}
+
 	// Infer locations
 	fmt.Println("\nInferring locations...")
-	inferences := processor.InferLocations(assets, devices)
+	inferences := processor.InferLocations(assets, devices, tracks)
 
 	// Filter by minimum confidence
 	filtered := 0
@@ -120,7 +131,7 @@ func runInfer(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func storeInferences(db *database.DB, inferences []processor.LocationInference, minConfidence float64) error {
+func storeInferences(db *database.DB, inferences []models.LocationInference, minConfidence float64) error {
 	tx, err := db.BeginTx()
 	if err != nil {
 		return err
@@ -0,0 +1,445 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/immich"
+	"github.com/jamo/immich-albums/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pushMinConfidence float64
+	pushDryRun        bool
+	pushBatchSize     int
+	revertPushes      bool
+)
+
+var pushLocationsCmd = &cobra.Command{
+	Use:   "push-locations",
+	Short: "Write inferred locations back to Immich",
+	Long: `Sets latitude and longitude in Immich for photos whose inferred location meets
+the confidence threshold, so they show up on Immich's map and places view.
+
+Photos that have GPS data, locally or in Immich, are never overwritten. Every change
+is recorded in a local journal first; run with --revert to restore the original
+values. Use --dry-run to see what would change.
+
+Immich cannot clear a location once it is set, so --revert cannot remove pushed
+coordinates from photos that had no GPS before. Those pushes stay in the journal,
+are reported as errors, and the coordinates keep being treated as inferred rather
+than as the photo's own GPS.`,
+	RunE: runPushLocations,
+}
+
+func init() {
+	rootCmd.AddCommand(pushLocationsCmd)
+
+	pushLocationsCmd.Flags().Float64Var(&pushMinConfidence, "min-confidence", 0.7, "Minimum inference confidence to push (0.0-1.0)")
+	pushLocationsCmd.Flags().BoolVar(&pushDryRun, "dry-run", false, "Show what would be changed without writing to Immich")
+	pushLocationsCmd.Flags().IntVar(&pushBatchSize, "batch-size", 100, "Number of assets to check and update per batch")
+	pushLocationsCmd.Flags().BoolVar(&revertPushes, "revert", false, "Restore the original locations of all pushed assets")
+}
+
+// locationGroup is a set of assets that get the same coordinates in one request
+type locationGroup struct {
+	Latitude  *float64
+	Longitude *float64
+	AssetIDs  []string
+}
+
+func runPushLocations(cmd *cobra.Command, args []string) error {
+	if pushBatchSize < 1 {
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	journal, err := db.GetLocationPushes()
+	if err != nil {
+		return fmt.Errorf("failed to get location journal: %w", err)
+	}
+
+	if revertPushes {
+		return revertLocations(db, journal)
+	}
+	return pushLocations(db, journal)
+}
+
+func pushLocations(db *database.DB, journal map[string]database.LocationPush) error {
+	fmt.Println("Loading assets and inferred locations from database...")
+	assets, err := db.GetAssets()
+	if err != nil {
+		return fmt.Errorf("failed to get assets: %w", err)
+	}
+	assetMap := make(map[string]models.Asset, len(assets))
+	for _, asset := range assets {
+		assetMap[asset.ID] = asset
+	}
+
+	inferences, err := db.GetInferredLocations()
+	if err != nil {
+		return fmt.Errorf("failed to get inferred locations: %w", err)
+	}
+
+	assetIDs := make([]string, 0, len(inferences))
+	for id := range inferences {
+		assetIDs = append(assetIDs, id)
+	}
+	sort.Strings(assetIDs)
+
+	// Select assets to push
+	var candidates []database.LocationPush
+	hasGPS, lowConfidence, alreadyPushed := 0, 0, 0
+	for _, id := range assetIDs {
+		inference := inferences[id]
+		asset, ok := assetMap[id]
+		if !ok {
+			continue
+		}
+		if asset.Latitude != nil && asset.Longitude != nil {
+			hasGPS++
+			continue
+		}
+		if inference.Confidence < pushMinConfidence {
+			lowConfidence++
+			continue
+		}
+
+		entry := database.LocationPush{
+			AssetID:         id,
+			PushedLatitude:  inference.Latitude,
+			PushedLongitude: inference.Longitude,
+			Confidence:      inference.Confidence,
+		}
+		if previous, ok := journal[id]; ok && previous.Active() {
+			if sameCoordinates(previous.PushedLatitude, previous.PushedLongitude, inference.Latitude, inference.Longitude) {
+				alreadyPushed++
+				continue
+			}
+			// Keep the values from before the first push so --revert restores them
+			entry.OriginalLatitude = previous.OriginalLatitude
+			entry.OriginalLongitude = previous.OriginalLongitude
+		}
+		candidates = append(candidates, entry)
+	}
+
+	fmt.Printf("Inferred locations: %d\n", len(inferences))
+	fmt.Printf("  To push (confidence >= %.2f): %d\n", pushMinConfidence, len(candidates))
+	fmt.Printf("  Already pushed: %d\n", alreadyPushed)
+	fmt.Printf("  Below confidence threshold: %d\n", lowConfidence)
+	if hasGPS > 0 {
+		fmt.Printf("  Skipped, photo has GPS: %d\n", hasGPS)
+	}
+
+	if len(candidates) == 0 {
+		fmt.Println("\nNothing to push")
+		return nil
+	}
+
+	if pushDryRun {
+		fmt.Println("\nDry run, no changes made. Assets that would be updated:")
+		printPushSample(candidates, assetMap)
+		return nil
+	}
+
+	client := immich.NewClient(immichURL, immichAPIKey)
+
+	pushed, gpsInImmich, missing, errors := 0, 0, 0, 0
+	batches := (len(candidates) + pushBatchSize - 1) / pushBatchSize
+	for b := 0; b < batches; b++ {
+		batch := candidates[b*pushBatchSize : min(len(candidates), (b+1)*pushBatchSize)]
+		fmt.Printf("\n[%d/%d] Checking %d assets in Immich...\n", b+1, batches, len(batch))
+
+		// Re-check Immich: the local copy may be older than a location set there since
+		var toPush []database.LocationPush
+		for _, entry := range batch {
+			current, err := client.GetAsset(entry.AssetID)
+			if err != nil {
+				if isNotFound(err) {
+					missing++
+				} else {
+					fmt.Printf("        ❌ Error fetching asset %s: %v\n", entry.AssetID, err)
+					errors++
+				}
+				continue
+			}
+			if current.Latitude != nil && current.Longitude != nil {
+				previous, ok := journal[entry.AssetID]
+				if !ok || !previous.Active() || !sameCoordinates(*current.Latitude, *current.Longitude, previous.PushedLatitude, previous.PushedLongitude) {
+					gpsInImmich++
+					continue
+				}
+			}
+			entry.PushedAt = time.Now()
+			toPush = append(toPush, entry)
+		}
+
+		if len(toPush) == 0 {
+			continue
+		}
+
+		// Journal first so an interrupted run can still be reverted
+		if err := db.StoreLocationPushes(toPush); err != nil {
+			return fmt.Errorf("failed to write location journal: %w", err)
+		}
+
+		groups := groupLocations(toPush, func(p database.LocationPush) (*float64, *float64) {
+			lat, lon := p.PushedLatitude, p.PushedLongitude
+			return &lat, &lon
+		})
+		updated := 0
+		for _, group := range groups {
+			if err := client.UpdateAssetsLocation(group.AssetIDs, group.Latitude, group.Longitude); err != nil {
+				fmt.Printf("        ❌ Error updating %d assets: %v\n", len(group.AssetIDs), err)
+				errors += len(group.AssetIDs)
+				rollbackJournal(db, journal, group.AssetIDs)
+				continue
+			}
+			updated += len(group.AssetIDs)
+		}
+		pushed += updated
+		fmt.Printf("        ✓ Updated %d assets in %d requests\n", updated, len(groups))
+	}
+
+	// Print summary
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("PUSH LOCATIONS SUMMARY")
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("  Locations pushed: %d\n", pushed)
+	if gpsInImmich > 0 {
+		fmt.Printf("  Skipped, GPS already set in Immich: %d\n", gpsInImmich)
+	}
+	if missing > 0 {
+		fmt.Printf("  Skipped, no longer in Immich: %d\n", missing)
+	}
+	if errors > 0 {
+		fmt.Printf("  Errors: %d\n", errors)
+	}
+
+	fmt.Println("\n✓ Push complete! Run 'push-locations --revert' to undo")
+
+	return nil
+}
+
+func revertLocations(db *database.DB, journal map[string]database.LocationPush) error {
+	var active []database.LocationPush
+	for _, entry := range journal {
+		if entry.Active() {
+			active = append(active, entry)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].AssetID < active[j].AssetID
+	})
+
+	if len(active) == 0 {
+		fmt.Println("No pushed locations to revert")
+		return nil
+	}
+
+	fmt.Printf("Pushed locations to revert: %d\n", len(active))
+
+	if pushDryRun {
+		fmt.Println("\nDry run, no changes made")
+		return nil
+	}
+
+	client := immich.NewClient(immichURL, immichAPIKey)
+
+	reverted, changed, missing, notRestored, errors := 0, 0, 0, 0, 0
+	batches := (len(active) + pushBatchSize - 1) / pushBatchSize
+	for b := 0; b < batches; b++ {
+		batch := active[b*pushBatchSize : min(len(active), (b+1)*pushBatchSize)]
+		fmt.Printf("\n[%d/%d] Checking %d assets in Immich...\n", b+1, batches, len(batch))
+
+		var toRevert []database.LocationPush
+		var gone []string
+		for _, entry := range batch {
+			current, err := client.GetAsset(entry.AssetID)
+			if err != nil {
+				if isNotFound(err) {
+					gone = append(gone, entry.AssetID)
+					missing++
+				} else {
+					fmt.Printf("        ❌ Error fetching asset %s: %v\n", entry.AssetID, err)
+					errors++
+				}
+				continue
+			}
+			// Leave locations that were edited in Immich after the push alone
+			if current.Latitude == nil || current.Longitude == nil ||
+				!sameCoordinates(*current.Latitude, *current.Longitude, entry.PushedLatitude, entry.PushedLongitude) {
+				changed++
+				continue
+			}
+			toRevert = append(toRevert, entry)
+		}
+
+		if len(gone) > 0 {
+			if err := db.MarkLocationPushesReverted(gone, time.Now()); err != nil {
+				return fmt.Errorf("failed to update location journal: %w", err)
+			}
+		}
+
+		groups := groupLocations(toRevert, func(p database.LocationPush) (*float64, *float64) {
+			return p.OriginalLatitude, p.OriginalLongitude
+		})
+		for _, group := range groups {
+			if err := client.UpdateAssetsLocation(group.AssetIDs, group.Latitude, group.Longitude); err != nil {
+				fmt.Printf("        ❌ Error reverting %d assets: %v\n", len(group.AssetIDs), err)
+				errors += len(group.AssetIDs)
+				continue
+			}
+
+			// Immich ignores null coordinates, so only trust what it actually stored
+			var restored []string
+			for _, id := range group.AssetIDs {
+				current, err := client.GetAsset(id)
+				if err != nil {
+					fmt.Printf("        ❌ Error checking asset %s: %v\n", id, err)
+					errors++
+					continue
+				}
+				if !hasLocation(current, group.Latitude, group.Longitude) {
+					notRestored++
+					errors++
+					continue
+				}
+				restored = append(restored, id)
+			}
+			if len(restored) == 0 {
+				continue
+			}
+			if err := db.MarkLocationPushesReverted(restored, time.Now()); err != nil {
+				return fmt.Errorf("failed to update location journal: %w", err)
+			}
+			reverted += len(restored)
+		}
+	}
+
+	// Print summary
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("REVERT LOCATIONS SUMMARY")
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("  Locations reverted: %d\n", reverted)
+	if changed > 0 {
+		fmt.Printf("  Skipped, location changed in Immich since the push: %d\n", changed)
+	}
+	if missing > 0 {
+		fmt.Printf("  Skipped, no longer in Immich: %d\n", missing)
+	}
+	if notRestored > 0 {
+		fmt.Printf("  Not restored, Immich kept the pushed location: %d\n", notRestored)
+		fmt.Println("    Immich cannot clear locations; these stay in the journal and keep being treated as inferred")
+	}
+	if errors > 0 {
+		fmt.Printf("  Errors: %d\n", errors)
+	}
+
+	fmt.Println("\n✓ Revert complete!")
+
+	return nil
+}
+
+// groupLocations groups journal entries by the coordinates to send, in first-seen order
+func groupLocations(entries []database.LocationPush, location func(database.LocationPush) (*float64, *float64)) []locationGroup {
+	var groups []locationGroup
+	index := make(map[string]int)
+
+	for _, entry := range entries {
+		lat, lon := location(entry)
+		key := "none"
+		if lat != nil && lon != nil {
+			key = fmt.Sprintf("%.7f,%.7f", *lat, *lon)
+		} else {
+			lat, lon = nil, nil
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, locationGroup{Latitude: lat, Longitude: lon})
+		}
+		groups[i].AssetIDs = append(groups[i].AssetIDs, entry.AssetID)
+	}
+
+	return groups
+}
+
+// rollbackJournal restores the journal entries of assets whose update failed
+func rollbackJournal(db *database.DB, journal map[string]database.LocationPush, assetIDs []string) {
+	var restore []database.LocationPush
+	var remove []string
+	for _, id := range assetIDs {
+		if previous, ok := journal[id]; ok {
+			restore = append(restore, previous)
+		} else {
+			remove = append(remove, id)
+		}
+	}
+
+	if err := db.StoreLocationPushes(restore); err != nil {
+		fmt.Printf("        ⚠️  Warning: Failed to restore journal entries: %v\n", err)
+	}
+	if err := db.DeleteLocationPushes(remove); err != nil {
+		fmt.Printf("        ⚠️  Warning: Failed to remove journal entries: %v\n", err)
+	}
+}
+
+// printPushSample prints up to 20 planned updates
+func printPushSample(candidates []database.LocationPush, assets map[string]models.Asset) {
+	const limit = 20
+	for i, entry := range candidates {
+		if i == limit {
+			fmt.Printf("  ... and %d more\n", len(candidates)-limit)
+			break
+		}
+		asset := assets[entry.AssetID]
+		fmt.Printf("  - %s (%s): %.5f, %.5f (confidence %.2f)\n", asset.OriginalFileName,
+			asset.LocalDateTime.Format("2006-01-02 15:04"), entry.PushedLatitude, entry.PushedLongitude, entry.Confidence)
+	}
+}
+
+// stripPushedLocations clears coordinates on freshly fetched assets that only have them
+// because push-locations wrote them, so they keep being treated as inferred.
+// Returns the number of assets changed.
+func stripPushedLocations(assets []models.Asset, journal map[string]database.LocationPush) int {
+	stripped := 0
+	for i, asset := range assets {
+		entry, ok := journal[asset.ID]
+		if !ok || !entry.Active() || asset.Latitude == nil || asset.Longitude == nil {
+			continue
+		}
+		if sameCoordinates(*asset.Latitude, *asset.Longitude, entry.PushedLatitude, entry.PushedLongitude) {
+			assets[i].Latitude = nil
+			assets[i].Longitude = nil
+			stripped++
+		}
+	}
+	return stripped
+}
+
+// hasLocation reports whether an asset is at the given coordinates, or has no location when
+// they are nil
+func hasLocation(asset models.Asset, lat, lon *float64) bool {
+	if lat == nil || lon == nil {
+		return asset.Latitude == nil || asset.Longitude == nil
+	}
+	return asset.Latitude != nil && asset.Longitude != nil && sameCoordinates(*asset.Latitude, *asset.Longitude, *lat, *lon)
+}
+
+// sameCoordinates compares coordinates allowing for rounding in Immich (about 1 m)
+func sameCoordinates(lat1, lon1, lat2, lon2 float64) bool {
+	return math.Abs(lat1-lat2) < 1e-5 && math.Abs(lon1-lon2) < 1e-5
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+var reassignDevicesCmd = &cobra.Command{
+	Use:   "reassign-devices",
+	Short: "Recompute which device took each photo",
+	Long: `Resolves the device of every asset from its make, model and filename counter
+and stores it with the asset. 'discover' does this automatically; run this command
+after devices were split or merged to refresh the stored assignments.`,
+	RunE: runReassignDevices,
+}
+
+func init() {
+	rootCmd.AddCommand(reassignDevicesCmd)
+}
+
+func runReassignDevices(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if err := assignDeviceKeys(db); err != nil {
+		return err
+	}
+
+	fmt.Println("\n✓ Device assignment complete!")
+	return nil
+}
+
+// assignDeviceKeys resolves and stores the device of every asset in the database
+func assignDeviceKeys(db *database.DB) error {
+	fmt.Println("Loading assets and devices from database...")
+	assets, err := db.GetAssets()
+	if err != nil {
+		return fmt.Errorf("failed to get assets: %w", err)
+	}
+
+	devices, err := db.GetDevices()
+	if err != nil {
+		return fmt.Errorf("failed to get devices: %w", err)
+	}
+
+	fmt.Printf("Assigning %d assets to %d devices...\n", len(assets), len(devices))
+	assignments := processor.AssignDevices(assets, devices)
+
+	if err := db.UpdateAssetDeviceKeys(assignments); err != nil {
+		return fmt.Errorf("failed to store device assignments: %w", err)
+	}
+
+	// Summarize per device
+	counts := make(map[string]int)
+	for _, deviceID := range assignments {
+		counts[deviceID]++
+	}
+	deviceIDs := make([]string, 0, len(counts))
+	for deviceID := range counts {
+		deviceIDs = append(deviceIDs, deviceID)
+	}
+	sort.Strings(deviceIDs)
+
+	fmt.Printf("Assigned %d assets (%d without a device)\n", len(assignments), len(assets)-len(assignments))
+	for _, deviceID := range deviceIDs {
+		fmt.Printf("  - %s: %d photos\n", deviceID, counts[deviceID])
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/models"
+	"github.com/jamo/immich-albums/internal/processor"
+)
+
+// reconcileRemovedAssets deletes assets that are gone from Immich and updates the
+// sessions and trips that contained them, so create-albums never adds a deleted asset
+func reconcileRemovedAssets(db *database.DB, removedIDs []string) error {
+	if len(removedIDs) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\nRemoving %d assets that were deleted, trashed or archived in Immich...\n", len(removedIDs))
+	if err := db.DeleteAssets(removedIDs); err != nil {
+		return err
+	}
+
+	removed := make(map[string]bool, len(removedIDs))
+	for _, id := range removedIDs {
+		removed[id] = true
+	}
+
+	assets, err := db.GetAssets()
+	if err != nil {
+		return err
+	}
+	assets, err = applyClockOffsets(db, assets)
+	if err != nil {
+		return err
+	}
+	assetMap := make(map[string]models.Asset, len(assets))
+	for _, asset := range assets {
+		assetMap[asset.ID] = asset
+	}
+
+	inferences, err := db.GetInferredLocations()
+	if err != nil {
+		return err
+	}
+
+	// Recompute sessions that lost assets
+	sessions, err := db.GetSessions()
+	if err != nil {
+		return err
+	}
+	keptSessions, affectedSessions := processor.PruneSessions(sessions, removed, assetMap, inferences)
+	if affectedSessions > 0 {
+		if err := db.StoreSessions(keptSessions); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("  Sessions updated: %d (%d removed)\n", affectedSessions, len(sessions)-len(keptSessions))
+
+	// Update trips in place so names, exclusions and album IDs survive
+	trips, err := db.GetTrips()
+	if err != nil {
+		return err
+	}
+	changedTrips, emptiedTrips := processor.PruneTrips(trips, removed, assetMap)
+	for i := range changedTrips {
+		if err := db.UpdateTrip(&changedTrips[i]); err != nil {
+			return err
+		}
+	}
+	for _, id := range emptiedTrips {
+		if err := db.DeleteTrip(id); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("  Trips updated: %d (%d removed)\n", len(changedTrips), len(emptiedTrips))
+
+	return nil
+}
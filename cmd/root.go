@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/jamo/immich-albums/internal/i18n"
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 )
@@ -12,6 +13,8 @@ var (
 	immichURL    string
 	immichAPIKey string
 	dbPath       string
+	appLocale    string
+	localeDir    string
 )
 
 var rootCmd = &cobra.Command{
@@ -33,6 +36,8 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&immichURL, "immich-url", os.Getenv("IMMICH_URL"), "Immich instance URL (can be set via IMMICH_URL env var)")
 	rootCmd.PersistentFlags().StringVar(&immichAPIKey, "api-key", os.Getenv("IMMICH_API_KEY"), "Immich API key (can be set via IMMICH_API_KEY env var)")
 	rootCmd.PersistentFlags().StringVar(&dbPath, "db", "./immich-albums.db", "Path to local SQLite database")
+	rootCmd.PersistentFlags().StringVar(&appLocale, "locale", envOr("ALBUMS_LOCALE", i18n.DefaultLocale), "Language of trip names and album descriptions, e.g. en or fi (can be set via ALBUMS_LOCALE env var)")
+	rootCmd.PersistentFlags().StringVar(&localeDir, "locale-dir", envOr("ALBUMS_LOCALE_DIR", "./locales"), "Directory with additional message catalogs (can be set via ALBUMS_LOCALE_DIR env var)")
 
 	// Add a pre-run check to ensure credentials are provided
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
@@ -45,3 +50,20 @@ func init() {
 		return nil
 	}
 }
+
+// envOr returns the value of an environment variable, or def when it is not set
+func envOr(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// loadCatalog loads the message catalog of the --locale language
+func loadCatalog() (*i18n.Catalog, error) {
+	catalog, err := i18n.Load(appLocale, localeDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load locale: %w", err)
+	}
+	return catalog, nil
+}
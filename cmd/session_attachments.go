@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var sessionAttachmentsCmd = &cobra.Command{
+	Use:   "session-attachments [SESSION_ID]",
+	Short: "List photos attached to sessions by time and why",
+	Long: `Lists the photos that 'detect-sessions --attach-unlocated' added to sessions because
+they were taken during the session but had no usable location or no photographer, with
+the reason each one was attached. Without a session ID all sessions are listed.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSessionAttachments,
+}
+
+func init() {
+	rootCmd.AddCommand(sessionAttachmentsCmd)
+}
+
+func runSessionAttachments(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	var sessions []models.Session
+	if len(args) == 1 {
+		session, err := getSessionArg(db, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get session: %w", err)
+		}
+		sessions = []models.Session{*session}
+	} else {
+		sessions, err = db.GetSessions()
+		if err != nil {
+			return fmt.Errorf("failed to get sessions: %w", err)
+		}
+	}
+
+	total := 0
+	for _, session := range sessions {
+		if len(session.Attachments) == 0 {
+			continue
+		}
+		fmt.Printf("Session %d: %s - %s, %s (%d photos)\n", session.ID,
+			session.StartTime.Format("2006-01-02 15:04"), session.EndTime.Format("2006-01-02 15:04"),
+			session.Photographer, len(session.AssetIDs))
+		for _, a := range session.Attachments {
+			fmt.Printf("  - %s: %s\n", a.AssetID, a.Reason)
+		}
+		total += len(session.Attachments)
+	}
+
+	if total == 0 {
+		fmt.Println("No photos attached by time. Run 'detect-sessions --attach-unlocated' to attach them.")
+		return nil
+	}
+	fmt.Printf("\n%d photos attached by time\n", total)
+	return nil
+}
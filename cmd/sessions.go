@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/jamo/immich-albums/internal/database"
 	"github.com/jamo/immich-albums/internal/models"
@@ -10,19 +12,39 @@ import (
 )
 
 var (
-	maxTimeGap       float64
-	maxDistance      float64
-	minPhotos        int
-	mergeSessions    bool
-	mergeTimeGap     float64
-	mergeDistance    float64
+	maxTimeGap           float64
+	maxDistance          float64
+	minPhotos            int
+	sessionMinConfidence float64
+	mergeSessions        bool
+	mergeTimeGap         float64
+	mergeDistance        float64
+	sessionAlgorithm     string
+	minNeighbors         int
+	showOutliers         int
+	attachUnlocated      bool
+	attachMarginHours    float64
+	attachPhotographers  string
 )
 
 var sessionsCmd = &cobra.Command{
 	Use:   "detect-sessions",
 	Short: "Detect photo sessions using spatial-temporal clustering",
 	Long: `Groups photos into sessions based on time proximity and geographic location.
-Sessions are detected per photographer and can optionally be merged across photographers.`,
+Sessions are detected per photographer and can optionally be merged across photographers.
+
+Algorithms:
+  linear     Starts a new session as soon as two consecutive photos are too far apart
+             in time or distance. One photo with a bad GPS fix splits a session.
+  st-dbscan  Density-based clustering: photos need --min-neighbors photos within
+             --max-time-gap and --max-distance. Isolated photos, and groups smaller than
+             --min-photos, are reported as outliers and left out instead of splitting
+             the session around them.
+
+With --attach-unlocated, photos that clustering can't place (no GPS and no confident
+inferred location, or taken with an unlabeled device) are attached to a session taken
+at the same time, within --attach-margin of its start or end. The reason for each
+attachment is stored and can be listed with 'session-attachments'.`,
 	RunE: runSessions,
 }
 
@@ -32,9 +54,16 @@ func init() {
 	sessionsCmd.Flags().Float64Var(&maxTimeGap, "max-time-gap", 6.0, "Maximum time gap between photos in hours")
 	sessionsCmd.Flags().Float64Var(&maxDistance, "max-distance", 5.0, "Maximum distance between photos in km")
 	sessionsCmd.Flags().IntVar(&minPhotos, "min-photos", 2, "Minimum photos required for a session")
+	sessionsCmd.Flags().Float64Var(&sessionMinConfidence, "min-confidence", 0.3, "Minimum confidence for inferred locations (0.0-1.0)")
 	sessionsCmd.Flags().BoolVar(&mergeSessions, "merge", false, "Merge sessions from different photographers")
 	sessionsCmd.Flags().Float64Var(&mergeTimeGap, "merge-time-gap", 2.0, "Time gap for merging sessions in hours")
 	sessionsCmd.Flags().Float64Var(&mergeDistance, "merge-distance", 1.0, "Distance for merging sessions in km")
+	sessionsCmd.Flags().StringVar(&sessionAlgorithm, "algorithm", processor.AlgorithmLinear, "Clustering algorithm: linear or st-dbscan")
+	sessionsCmd.Flags().IntVar(&minNeighbors, "min-neighbors", 3, "st-dbscan: photos (including itself) within both limits for a photo to anchor a session")
+	sessionsCmd.Flags().IntVar(&showOutliers, "show-outliers", 10, "Number of outlier photos to list")
+	sessionsCmd.Flags().BoolVar(&attachUnlocated, "attach-unlocated", false, "Attach photos without a usable location to sessions taken at the same time")
+	sessionsCmd.Flags().Float64Var(&attachMarginHours, "attach-margin", 1.0, "How far before or after a session a photo may be attached, in hours")
+	sessionsCmd.Flags().StringVar(&attachPhotographers, "attach-photographers", processor.AttachSamePhotographer, "Attach to sessions of the 'same' photographer only, or of 'any' photographer")
 }
 
 func runSessions(cmd *cobra.Command, args []string) error {
@@ -51,6 +80,10 @@ func runSessions(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get assets: %w", err)
 	}
 	fmt.Printf("Loaded %d assets\n", len(assets))
+	assets, err = applyClockOffsets(db, assets)
+	if err != nil {
+		return err
+	}
 
 	// Load devices
 	devices, err := db.GetDevices()
@@ -64,18 +97,27 @@ func runSessions(cmd *cobra.Command, args []string) error {
 		deviceMap[d.ID] = d
 	}
 
-	// Build inference map from database
-	// For now, the inferred locations are stored directly in the assets table
-	// We'll build the map by checking which assets have inferred locations
-	inferenceMap := make(map[string]processor.LocationInference)
-	// TODO: Load inferred locations from database into inferenceMap
+	// Load inferred locations stored by infer-locations
+	inferenceMap, err := db.GetInferredLocations()
+	if err != nil {
+		return fmt.Errorf("failed to get inferred locations: %w", err)
+	}
+	fmt.Printf("Loaded %d inferred locations\n", len(inferenceMap))
 
 	// Set clustering parameters
 	params := processor.ClusteringParams{
 		MaxTimeGapHours:    maxTimeGap,
 		MaxDistanceKM:      maxDistance,
 		MinPhotosInSession: minPhotos,
-		MinConfidence:      0.3,
+		MinConfidence:      sessionMinConfidence,
+		Algorithm:          sessionAlgorithm,
+		MinNeighbors:       minNeighbors,
+	}
+	if _, err := processor.NewSessionClusterer(params); err != nil {
+		return err
+	}
+	if attachPhotographers != processor.AttachSamePhotographer && attachPhotographers != processor.AttachAnyPhotographer {
+		return fmt.Errorf("invalid --attach-photographers %q (expected 'same' or 'any')", attachPhotographers)
 	}
 
 	fmt.Println("\nDetecting sessions...")
@@ -84,8 +126,16 @@ func runSessions(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Max distance: %.1f km\n", params.MaxDistanceKM)
 	fmt.Printf("  Min photos: %d\n", params.MinPhotosInSession)
 	fmt.Printf("  Min confidence: %.2f\n", params.MinConfidence)
+	fmt.Printf("  Algorithm: %s\n", params.Algorithm)
+	if params.Algorithm == processor.AlgorithmSTDBSCAN {
+		fmt.Printf("  Min neighbors: %d\n", params.MinNeighbors)
+	}
 
-	sessions := processor.DetectSessions(assets, inferenceMap, deviceMap, params)
+	sessions, outliers, err := processor.DetectSessions(assets, inferenceMap, deviceMap, params)
+	if err != nil {
+		return err
+	}
+	printOutliers(outliers, showOutliers)
 
 	if mergeSessions && len(sessions) > 1 {
 		fmt.Printf("\nMerging sessions across photographers...\n")
@@ -96,6 +146,21 @@ func runSessions(cmd *cobra.Command, args []string) error {
 		fmt.Printf("After merging: %d sessions\n", len(sessions))
 	}
 
+	attached := 0
+	if attachUnlocated {
+		fmt.Printf("\nAttaching photos without a usable location...\n")
+		fmt.Printf("  Margin: %.1f hours\n", attachMarginHours)
+		fmt.Printf("  Photographers: %s\n", attachPhotographers)
+
+		attached = processor.AttachUnlocatedAssets(sessions, assets, inferenceMap, deviceMap, processor.AttachParams{
+			Margin:        time.Duration(attachMarginHours * float64(time.Hour)),
+			Photographers: attachPhotographers,
+			MinConfidence: sessionMinConfidence,
+			MaxDistanceKM: maxDistance,
+		})
+		fmt.Printf("Attached %d photos to sessions\n", attached)
+	}
+
 	// Store sessions
 	fmt.Println("\nStoring sessions in database...")
 	if err := db.StoreSessions(sessions); err != nil {
@@ -117,8 +182,35 @@ func runSessions(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Average photos per session: %.1f\n", avgPhotos)
 	}
 
+	if attached > 0 {
+		fmt.Printf("  Photos attached by time: %d\n", attached)
+	}
+	if len(outliers) > 0 {
+		fmt.Printf("  Outlier photos left out: %d\n", len(outliers))
+	}
+
 	fmt.Println("\n✓ Session detection complete!")
 	fmt.Println("Next: Run 'serve' to visualize sessions and label home locations")
 
 	return nil
 }
+
+// printOutliers lists up to limit outlier photos in time order
+func printOutliers(outliers []processor.SessionOutlier, limit int) {
+	if len(outliers) == 0 || limit <= 0 {
+		return
+	}
+	sort.Slice(outliers, func(i, j int) bool {
+		return outliers[i].Asset.Asset.LocalDateTime.Before(outliers[j].Asset.Asset.LocalDateTime)
+	})
+
+	fmt.Printf("\n⚠️  %d outlier photos don't fit any session (bad GPS fix or a lone photo):\n", len(outliers))
+	for i, outlier := range outliers {
+		if i == limit {
+			fmt.Printf("  ... and %d more (use --show-outliers to list more)\n", len(outliers)-limit)
+			break
+		}
+		fmt.Printf("  - %s %s at %.5f, %.5f (%s)\n", outlier.Asset.Asset.LocalDateTime.Format("2006-01-02 15:04"),
+			outlier.Photographer, outlier.Asset.Latitude, outlier.Asset.Longitude, outlier.Asset.Asset.ID)
+	}
+}
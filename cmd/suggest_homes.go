@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	suggestMinDays int
+	suggestLimit   int
+)
+
+var suggestHomesCmd = &cobra.Command{
+	Use:   "suggest-homes",
+	Short: "Suggest home locations and frequent places from photo history",
+	Long: `Finds places photographed on many different days, such as your home, a cabin,
+your parents' house or the office. Places with photos taken at night on many days are
+marked as likely homes. Places already covered by a home location are skipped.
+
+Add suggestions with one click on the homes page of the web UI.`,
+	RunE: runSuggestHomes,
+}
+
+func init() {
+	rootCmd.AddCommand(suggestHomesCmd)
+
+	defaults := processor.DefaultPlaceSuggestionParams()
+	suggestHomesCmd.Flags().IntVar(&suggestMinDays, "min-days", defaults.MinDays, "Minimum distinct days with photos at a place")
+	suggestHomesCmd.Flags().IntVar(&suggestLimit, "limit", defaults.MaxSuggestions, "Maximum number of suggestions")
+}
+
+func runSuggestHomes(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	fmt.Println("Loading assets from database...")
+	assets, err := db.GetAssets()
+	if err != nil {
+		return fmt.Errorf("failed to get assets: %w", err)
+	}
+	homes, err := db.GetHomeLocations()
+	if err != nil {
+		return fmt.Errorf("failed to get home locations: %w", err)
+	}
+
+	params := processor.DefaultPlaceSuggestionParams()
+	params.MinDays = suggestMinDays
+	params.MaxSuggestions = suggestLimit
+
+	suggestions := processor.SuggestPlaces(assets, homes, params)
+	if len(suggestions) == 0 {
+		fmt.Println("\nNo recurring places found outside your home locations")
+		return nil
+	}
+
+	fmt.Printf("\nFound %d recurring places:\n\n", len(suggestions))
+	for i, s := range suggestions {
+		kind := "Frequent place"
+		if s.Kind == processor.PlaceHome {
+			kind = "Likely home"
+		}
+		fmt.Printf("%d. %s at %.5f, %.5f (radius %.1fkm)\n", i+1, kind, s.Latitude, s.Longitude, s.Radius)
+		fmt.Printf("   %d photos on %d days, %d with night photos\n", s.PhotoCount, s.Days, s.NightDays)
+		fmt.Printf("   %s to %s\n", s.FirstSeen.Format("2006-01-02"), s.LastSeen.Format("2006-01-02"))
+	}
+
+	fmt.Println("\nAdd them on the homes page: run 'serve' and open http://localhost:8080/homes")
+	return nil
+}
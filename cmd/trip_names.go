@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/models"
+	"github.com/jamo/immich-albums/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tripNameTemplate string
+	renameDryRun     bool
+)
+
+const tripNameTemplateHelp = "Trip name template: a built-in one (default, route, countries), a Go text/template, or @FILE (can be set via TRIP_NAME_TEMPLATE env var)"
+
+var tripRenameCmd = &cobra.Command{
+	Use:   "rename",
+	Short: "Rename trips with a name template",
+	Long: `Renames the detected trips with a Go text/template, without detecting them again.
+Trips renamed by hand keep their names.
+
+Templates can use .Location, .Cities, .Countries, .Start, .End, .Duration, .Days,
+.Nights, .Photographers and .Category (day trip, weekend, short trip or long trip), and
+the helpers route, routeMax, list, join, first, last, dates, date, month, destination
+and t (a message from the --locale catalog). For example:
+
+  {{routeMax 3 .Cities}} - {{dates .Start .End}}      Paris → Lyon → Nice - Jun 1-5, 2024
+  {{destination .}} {{.Start.Year}}                    France and Italy 2024`,
+	RunE: runTripRename,
+}
+
+func init() {
+	tripRulesCmd.AddCommand(tripRenameCmd)
+
+	tripRenameCmd.Flags().StringVar(&tripNameTemplate, "name-template", os.Getenv("TRIP_NAME_TEMPLATE"), tripNameTemplateHelp)
+	tripRenameCmd.Flags().BoolVar(&renameDryRun, "dry-run", false, "Show the new names without saving them")
+}
+
+// loadTripNamer parses a trip name template given by name, as text or as @FILE
+func loadTripNamer(value string) (*processor.TripNamer, error) {
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trip name template: %w", err)
+		}
+		value = strings.TrimSpace(string(data))
+	}
+	catalog, err := loadCatalog()
+	if err != nil {
+		return nil, err
+	}
+	return processor.NewTripNamer(value, catalog)
+}
+
+func runTripRename(cmd *cobra.Command, args []string) error {
+	namer, err := loadTripNamer(tripNameTemplate)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	trips, err := db.GetTrips()
+	if err != nil {
+		return fmt.Errorf("failed to get trips: %w", err)
+	}
+	if len(trips) == 0 {
+		return fmt.Errorf("no trips found. Run 'detect-trips' first")
+	}
+	assets, err := db.GetAssets()
+	if err != nil {
+		return fmt.Errorf("failed to get assets: %w", err)
+	}
+	inferences, err := db.GetInferredLocations()
+	if err != nil {
+		return fmt.Errorf("failed to get inferred locations: %w", err)
+	}
+	zones, err := db.GetZones(models.ZonePrivacy)
+	if err != nil {
+		return fmt.Errorf("failed to get zones: %w", err)
+	}
+	private := processor.PrivateAssets(assets, inferences, zones)
+
+	previous := make([]string, len(trips))
+	for i, trip := range trips {
+		previous[i] = trip.Name
+	}
+	if err := processor.NameTrips(trips, assets, private, namer); err != nil {
+		return err
+	}
+
+	renamed, custom := 0, 0
+	for i := range trips {
+		if trips[i].CustomName {
+			custom++
+			continue
+		}
+		if trips[i].Name == previous[i] {
+			continue
+		}
+		fmt.Printf("  %q -> %q\n", previous[i], trips[i].Name)
+		renamed++
+		if renameDryRun {
+			continue
+		}
+		if err := db.UpdateTrip(&trips[i]); err != nil {
+			return fmt.Errorf("failed to update trip %d: %w", trips[i].ID, err)
+		}
+	}
+
+	if renameDryRun {
+		fmt.Printf("\n⏭️  Dry run: %d trips would be renamed, %d named by hand kept\n", renamed, custom)
+		return nil
+	}
+	fmt.Printf("\n✓ Renamed %d trips, kept %d named by hand\n", renamed, custom)
+	return nil
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/models"
+	"github.com/jamo/immich-albums/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	splitAtSession int64
+	splitAtTime    string
+)
+
+var tripRulesCmd = &cobra.Command{
+	Use:   "trips",
+	Short: "Merge, split and adjust trips",
+	Long: `Creates manual trip rules. Rules are stored in the database and applied in the
+order they were created every time detect-trips runs, so they survive changes to the
+detection parameters. Sessions and trips are identified by their photos, so rules also
+survive re-running detect-sessions.
+
+Run detect-trips after adding or deleting rules to apply them.`,
+}
+
+var tripMergeCmd = &cobra.Command{
+	Use:   "merge TRIP_ID TRIP_ID",
+	Short: "Merge two trips into one",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runTripMerge,
+}
+
+var tripSplitCmd = &cobra.Command{
+	Use:   "split TRIP_ID",
+	Short: "Split a trip at a session (--session) or a time (--at)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTripSplit,
+}
+
+var tripAttachCmd = &cobra.Command{
+	Use:   "attach TRIP_ID SESSION_ID",
+	Short: "Move a session into a trip",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runTripAttach,
+}
+
+var tripDetachCmd = &cobra.Command{
+	Use:   "detach SESSION_ID",
+	Short: "Remove a session from its trip",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTripDetach,
+}
+
+var tripRulesListCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "List trip rules",
+	RunE:  runTripRulesList,
+}
+
+var tripRulesDeleteCmd = &cobra.Command{
+	Use:   "delete-rule ID...",
+	Short: "Delete trip rules",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runTripRulesDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(tripRulesCmd)
+	tripRulesCmd.AddCommand(tripMergeCmd, tripSplitCmd, tripAttachCmd, tripDetachCmd, tripRulesListCmd, tripRulesDeleteCmd)
+
+	tripSplitCmd.Flags().Int64Var(&splitAtSession, "session", 0, "Session that starts the new trip")
+	tripSplitCmd.Flags().StringVar(&splitAtTime, "at", "", "Time that starts the new trip (YYYY-MM-DD or YYYY-MM-DD HH:MM, local photo time)")
+}
+
+func runTripMerge(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	a, err := getTripArg(db, args[0])
+	if err != nil {
+		return err
+	}
+	b, err := getTripArg(db, args[1])
+	if err != nil {
+		return err
+	}
+
+	rule, err := processor.NewMergeRule(*a, *b)
+	if err != nil {
+		return err
+	}
+	return storeTripRule(db, rule)
+}
+
+func runTripSplit(cmd *cobra.Command, args []string) error {
+	if (splitAtSession == 0) == (splitAtTime == "") {
+		return fmt.Errorf("specify exactly one of --session or --at")
+	}
+
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	trip, err := getTripArg(db, args[0])
+	if err != nil {
+		return err
+	}
+
+	var session *models.Session
+	var at time.Time
+	if splitAtSession != 0 {
+		session, err = db.GetSession(splitAtSession)
+		if err != nil {
+			return err
+		}
+	} else {
+		at, err = parseSplitTime(splitAtTime)
+		if err != nil {
+			return err
+		}
+	}
+
+	rule, err := processor.NewSplitRule(*trip, session, at)
+	if err != nil {
+		return err
+	}
+	return storeTripRule(db, rule)
+}
+
+func runTripAttach(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	trip, err := getTripArg(db, args[0])
+	if err != nil {
+		return err
+	}
+	session, err := getSessionArg(db, args[1])
+	if err != nil {
+		return err
+	}
+
+	rule, err := processor.NewAttachRule(*trip, *session)
+	if err != nil {
+		return err
+	}
+	return storeTripRule(db, rule)
+}
+
+func runTripDetach(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	session, err := getSessionArg(db, args[0])
+	if err != nil {
+		return err
+	}
+
+	rule, err := processor.NewDetachRule(*session)
+	if err != nil {
+		return err
+	}
+	return storeTripRule(db, rule)
+}
+
+func runTripRulesList(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	rules, err := db.GetTripRules()
+	if err != nil {
+		return fmt.Errorf("failed to get trip rules: %w", err)
+	}
+
+	if len(rules) == 0 {
+		fmt.Println("No trip rules. Add them with 'trips merge', 'trips split', 'trips attach' or 'trips detach'.")
+		return nil
+	}
+
+	for _, rule := range rules {
+		fmt.Printf("[%d] %s: %s\n", rule.ID, rule.Type, rule.Description)
+	}
+	return nil
+}
+
+func runTripRulesDelete(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid trip rule ID %q", arg)
+		}
+		if err := db.DeleteTripRule(id); err != nil {
+			return fmt.Errorf("failed to delete trip rule: %w", err)
+		}
+		fmt.Printf("✓ Deleted trip rule %d\n", id)
+	}
+
+	fmt.Println("\nRun 'detect-trips' to apply the change")
+	return nil
+}
+
+func storeTripRule(db *database.DB, rule models.TripRule) error {
+	id, err := db.StoreTripRule(rule)
+	if err != nil {
+		return fmt.Errorf("failed to store trip rule: %w", err)
+	}
+
+	fmt.Printf("✓ Added trip rule %d: %s\n", id, rule.Description)
+	fmt.Println("Run 'detect-trips' to apply it")
+	return nil
+}
+
+func getTripArg(db *database.DB, arg string) (*models.Trip, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trip ID %q", arg)
+	}
+	trip, err := db.GetTrip(id)
+	if err != nil {
+		return nil, fmt.Errorf("trip %d: %w", id, err)
+	}
+	return trip, nil
+}
+
+func getSessionArg(db *database.DB, arg string) (*models.Session, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session ID %q", arg)
+	}
+	return db.GetSession(id)
+}
+
+// parseSplitTime parses a date or date and time in local photo time
+func parseSplitTime(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (expected YYYY-MM-DD or YYYY-MM-DD HH:MM)", s)
+}
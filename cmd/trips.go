@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/models"
 	"github.com/jamo/immich-albums/internal/processor"
 	"github.com/spf13/cobra"
 )
@@ -17,6 +19,7 @@ var (
 	minSessionsInTrip   int
 	maxHomeStayHours    float64
 	splitDates          []string
+	awayPolicy          string
 )
 
 var tripsCmd = &cobra.Command{
@@ -39,6 +42,8 @@ func init() {
 	tripsCmd.Flags().IntVar(&minSessionsInTrip, "min-sessions", 1, "Minimum sessions required for a trip")
 	tripsCmd.Flags().Float64Var(&maxHomeStayHours, "max-home-stay", 36.0, "Maximum hours at home before trip splits (brief returns home like overnight stops)")
 	tripsCmd.Flags().StringSliceVar(&splitDates, "split-date", []string{}, "Force trip split at specific dates (format: 2024-07-15). Can be specified multiple times.")
+	tripsCmd.Flags().StringVar(&tripNameTemplate, "name-template", os.Getenv("TRIP_NAME_TEMPLATE"), tripNameTemplateHelp)
+	tripsCmd.Flags().StringVar(&awayPolicy, "away-policy", processor.AwayIfAny, "For sessions with several photographers: 'any' (away if anyone is away from their homes) or 'all' (away only if everyone is)")
 }
 
 func runTrips(cmd *cobra.Command, args []string) error {
@@ -48,6 +53,14 @@ func runTrips(cmd *cobra.Command, args []string) error {
 	}
 	defer db.Close()
 
+	if awayPolicy != processor.AwayIfAny && awayPolicy != processor.AwayIfAll {
+		return fmt.Errorf("invalid away policy %q (expected %q or %q)", awayPolicy, processor.AwayIfAny, processor.AwayIfAll)
+	}
+	namer, err := loadTripNamer(tripNameTemplate)
+	if err != nil {
+		return err
+	}
+
 	// Load sessions
 	fmt.Println("Loading sessions from database...")
 	sessions, err := db.GetSessions()
@@ -76,9 +89,26 @@ func runTrips(cmd *cobra.Command, args []string) error {
 	} else {
 		fmt.Printf("Loaded %d home locations\n", len(homes))
 		for _, home := range homes {
-			fmt.Printf("  - %s (%.4f, %.4f, %.1fkm radius)\n", home.Name, home.Latitude, home.Longitude, home.Radius)
+			who := "everyone"
+			if home.Photographer != "" {
+				who = home.Photographer
+			}
+			shape := fmt.Sprintf("%.1fkm radius", home.Radius)
+			if len(home.Area) > 0 {
+				shape = fmt.Sprintf("%d-point area", len(home.Area[0]))
+			}
+			fmt.Printf("  - %s (%.4f, %.4f, %s, %s, %s)\n", home.Name, home.Latitude, home.Longitude, shape,
+				dateRange(home.ValidFrom, home.ValidTo), who)
 		}
 	}
+
+	zones, err := db.GetZones(models.ZoneExclude)
+	if err != nil {
+		return fmt.Errorf("failed to get zones: %w", err)
+	}
+	if len(zones) > 0 {
+		fmt.Printf("Loaded %d exclusion zones\n", len(zones))
+	}
 	// Load assets for location extraction
 	fmt.Println("Loading assets from database...")
 	assets, err := db.GetAssets()
@@ -86,6 +116,30 @@ func runTrips(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get assets: %w", err)
 	}
 	fmt.Printf("Loaded %d assets\n", len(assets))
+	assets, err = applyClockOffsets(db, assets)
+	if err != nil {
+		return err
+	}
+
+	// Devices and inferred locations for the route summaries
+	devices, err := db.GetDevices()
+	if err != nil {
+		return fmt.Errorf("failed to get devices: %w", err)
+	}
+	deviceMap := make(map[string]models.Device)
+	for _, d := range devices {
+		deviceMap[d.ID] = d
+	}
+	inferenceMap, err := db.GetInferredLocations()
+	if err != nil {
+		return fmt.Errorf("failed to get inferred locations: %w", err)
+	}
+	// Photos inside privacy zones are left out of the summaries and names
+	privacyZones, err := db.GetZones(models.ZonePrivacy)
+	if err != nil {
+		return fmt.Errorf("failed to get zones: %w", err)
+	}
+	private := processor.PrivateAssets(assets, inferenceMap, privacyZones)
 
 	// Parse split dates
 	var parsedSplitDates []time.Time
@@ -102,6 +156,12 @@ func runTrips(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Load manual trip rules
+	rules, err := db.GetTripRules()
+	if err != nil {
+		return fmt.Errorf("failed to get trip rules: %w", err)
+	}
+
 	// Set up criteria
 	criteria := processor.TripCriteria{
 		MinDistanceFromHome: minDistanceFromHome,
@@ -110,6 +170,9 @@ func runTrips(cmd *cobra.Command, args []string) error {
 		MinSessions:         minSessionsInTrip,
 		MaxHomeStayDuration: time.Duration(maxHomeStayHours) * time.Hour,
 		ForceSplitDates:     parsedSplitDates,
+		Rules:               rules,
+		AwayPolicy:          awayPolicy,
+		ExclusionZones:      zones,
 	}
 
 	fmt.Println("\nDetecting trips...")
@@ -119,9 +182,13 @@ func runTrips(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Max home stay: %.0f hours (brief returns home don't split trips)\n", maxHomeStayHours)
 	fmt.Printf("  Min trip duration: %.0f hours\n", minTripDuration)
 	fmt.Printf("  Min sessions: %d\n", criteria.MinSessions)
+	fmt.Printf("  Away policy: %s\n", criteria.AwayPolicy)
 	if len(parsedSplitDates) > 0 {
 		fmt.Printf("  Forced split dates: %d\n", len(parsedSplitDates))
 	}
+	if len(rules) > 0 {
+		fmt.Printf("  Trip rules: %d (see 'trips rules')\n", len(rules))
+	}
 	fmt.Println()
 
 	// Detect trips
@@ -132,6 +199,17 @@ func runTrips(cmd *cobra.Command, args []string) error {
 		fmt.Println("Try adjusting parameters or ensure you have sessions away from home.")
 		return nil
 	}
+	processor.SummarizeTrips(trips, assets, inferenceMap, deviceMap, homes, private)
+	if err := processor.NameTrips(trips, assets, private, namer); err != nil {
+		return err
+	}
+
+	// Keep the identity of previously detected trips so names, exclusions and albums survive
+	previousTrips, err := db.GetTrips()
+	if err != nil {
+		return fmt.Errorf("failed to get previous trips: %w", err)
+	}
+	trips, changes := processor.MatchTrips(previousTrips, trips)
 
 	// Store trips
 	fmt.Println("\nStoring trips in database...")
@@ -139,6 +217,10 @@ func runTrips(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to store trips: %w", err)
 	}
 
+	if len(previousTrips) > 0 {
+		printTripChanges(changes)
+	}
+
 	// Print summary
 	fmt.Println("\n" + strings.Repeat("=", 60))
 	fmt.Println("TRIP DETECTION SUMMARY")
@@ -158,8 +240,19 @@ func runTrips(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  Duration: %.1f hours\n", duration.Hours())
 		}
 
-		fmt.Printf("  Distance from home: %.1fkm\n", trip.HomeDistance)
+		fmt.Printf("  Distance from home: %.1fkm (farthest %.1fkm)\n", trip.HomeDistance, trip.Summary.FarthestDistance)
 		fmt.Printf("  Travel distance: %.1fkm\n", trip.TotalDistance)
+		if len(trip.Summary.Cities) > 0 {
+			fmt.Printf("  Route: %s\n", processor.RouteString(trip.Summary.Cities))
+		}
+		if len(trip.Summary.Countries) > 0 {
+			fmt.Printf("  Countries: %s\n", strings.Join(trip.Summary.Countries, ", "))
+		}
+		for _, flight := range trip.Summary.Flights {
+			fmt.Printf("  Flight: %s (%.0fkm, %s)\n", processor.RouteString([]string{placeOrUnknown(flight.From), placeOrUnknown(flight.To)}),
+				flight.DistanceKM, flight.Departure.Format("Jan 2"))
+		}
+		fmt.Printf("  Nights away: %d\n", trip.Summary.NightsAway)
 		fmt.Printf("  Sessions: %d\n", trip.SessionCount)
 		fmt.Printf("  Photos: %d\n", len(trip.AssetIDs))
 		fmt.Printf("  Photographers: %s\n", trip.Photographers)
@@ -171,3 +264,56 @@ func runTrips(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// printTripChanges reports how the detected trips differ from the previous run
+func printTripChanges(changes []processor.TripChange) {
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Kind]++
+	}
+
+	fmt.Println("\nChanges since the last detection:")
+	fmt.Printf("  New: %d, changed: %d, unchanged: %d, split: %d, merged: %d, disappeared: %d\n",
+		counts[processor.TripNew], counts[processor.TripChanged], counts[processor.TripUnchanged],
+		counts[processor.TripSplit], counts[processor.TripMerged], counts[processor.TripDisappeared])
+
+	for _, change := range changes {
+		switch change.Kind {
+		case processor.TripNew:
+			fmt.Printf("  + New: %s\n", tripNames(change.Trips))
+		case processor.TripChanged:
+			fmt.Printf("  ~ Changed: %s\n", tripNames(change.Trips))
+		case processor.TripSplit:
+			fmt.Printf("  ✂️  Split: %s -> %s\n", tripNames(change.Previous), tripNames(change.Trips))
+		case processor.TripMerged:
+			fmt.Printf("  🔗 Merged: %s -> %s\n", tripNames(change.Previous), tripNames(change.Trips))
+			for _, previous := range change.Previous {
+				if previous.ID != change.Trips[0].ID && previous.AlbumID != "" {
+					fmt.Printf("     ⚠️  Album %s of %q is no longer linked to a trip\n", previous.AlbumID, previous.Name)
+				}
+			}
+		case processor.TripDisappeared:
+			fmt.Printf("  - Disappeared: %s\n", tripNames(change.Previous))
+			if change.Previous[0].AlbumID != "" {
+				fmt.Printf("     ⚠️  Album %s is no longer linked to a trip\n", change.Previous[0].AlbumID)
+			}
+		}
+	}
+}
+
+// tripNames formats trip names as a comma separated list
+func tripNames(trips []models.Trip) string {
+	names := make([]string, len(trips))
+	for i, trip := range trips {
+		names[i] = fmt.Sprintf("%q", trip.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// placeOrUnknown returns a place name, or "?" when it isn't known
+func placeOrUnknown(place string) string {
+	if place == "" {
+		return "?"
+	}
+	return place
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/geojson"
+	"github.com/jamo/immich-albums/internal/models"
+	"github.com/jamo/immich-albums/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	zoneImportAs string
+	zoneKind     string
+	zoneLat      float64
+	zoneLon      float64
+	zoneRadius   float64
+)
+
+var zonesCmd = &cobra.Command{
+	Use:   "zones",
+	Short: "Manage polygon home areas, exclusion zones and privacy zones",
+	Long: `Manages areas drawn as polygons. Polygon homes work like circular homes but follow
+the actual outline, e.g. a commute corridor or a lakeside property. Sessions inside an
+exclusion zone never count as away from home, so they never start or extend a trip.
+
+Photos inside a privacy zone are left out of albums by create-albums, and their locations
+are removed from or blurred in export-trip files. Privacy zones can be polygons or a point
+with a radius.
+
+Zones can also be drawn on the homes page of the web UI.`,
+}
+
+var zonesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List zones and polygon homes",
+	RunE:  runZonesList,
+}
+
+var zonesAddCmd = &cobra.Command{
+	Use:   "add NAME",
+	Short: "Add a circular zone around a point",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runZonesAdd,
+}
+
+var zonesImportCmd = &cobra.Command{
+	Use:   "import FILE.geojson",
+	Short: "Import polygons from a GeoJSON file as zones or homes",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runZonesImport,
+}
+
+var zonesDeleteCmd = &cobra.Command{
+	Use:   "delete ID...",
+	Short: "Delete zones",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runZonesDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(zonesCmd)
+	zonesCmd.AddCommand(zonesListCmd, zonesAddCmd, zonesImportCmd, zonesDeleteCmd)
+
+	zonesAddCmd.Flags().StringVar(&zoneKind, "kind", models.ZonePrivacy, "Zone kind: 'privacy' or 'exclude'")
+	zonesAddCmd.Flags().Float64Var(&zoneLat, "lat", 0, "Latitude of the zone center")
+	zonesAddCmd.Flags().Float64Var(&zoneLon, "lon", 0, "Longitude of the zone center")
+	zonesAddCmd.Flags().Float64Var(&zoneRadius, "radius", 0.2, "Zone radius in km")
+	zonesAddCmd.MarkFlagRequired("lat")
+	zonesAddCmd.MarkFlagRequired("lon")
+
+	zonesImportCmd.Flags().StringVar(&zoneImportAs, "as", models.ZoneExclude, "Import polygons as 'exclude' or 'privacy' zones, or as 'home' locations")
+}
+
+func runZonesList(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	zones, err := db.GetZones("")
+	if err != nil {
+		return fmt.Errorf("failed to get zones: %w", err)
+	}
+	homes, err := db.GetHomeLocations()
+	if err != nil {
+		return fmt.Errorf("failed to get home locations: %w", err)
+	}
+
+	fmt.Printf("Zones (%d):\n", len(zones))
+	for _, zone := range zones {
+		if len(zone.Area) == 0 {
+			fmt.Printf("  [%d] %s: %s (%.1fkm around %.4f, %.4f)\n", zone.ID, zone.Kind, zone.Name, zone.Radius, zone.Latitude, zone.Longitude)
+			continue
+		}
+		lat, lon := processor.PolygonCenter(zone.Area)
+		fmt.Printf("  [%d] %s: %s (%d points around %.4f, %.4f)\n", zone.ID, zone.Kind, zone.Name, len(zone.Area[0]), lat, lon)
+	}
+
+	fmt.Println("\nPolygon homes:")
+	for _, home := range homes {
+		if len(home.Area) > 0 {
+			fmt.Printf("  [%d] %s (%d points around %.4f, %.4f)\n", home.ID, home.Name, len(home.Area[0]), home.Latitude, home.Longitude)
+		}
+	}
+	return nil
+}
+
+func runZonesAdd(cmd *cobra.Command, args []string) error {
+	if !validZoneKind(zoneKind) {
+		return fmt.Errorf("invalid zone kind %q (expected %q or %q)", zoneKind, models.ZonePrivacy, models.ZoneExclude)
+	}
+
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	zone := models.Zone{
+		Name:      args[0],
+		Kind:      zoneKind,
+		Latitude:  zoneLat,
+		Longitude: zoneLon,
+		Radius:    zoneRadius,
+		CreatedAt: time.Now(),
+	}
+	id, err := db.StoreZone(zone)
+	if err != nil {
+		return fmt.Errorf("failed to store zone: %w", err)
+	}
+	fmt.Printf("✓ Added %s zone %d: %s (%.1fkm around %.4f, %.4f)\n", zone.Kind, id, zone.Name, zone.Radius, zone.Latitude, zone.Longitude)
+	return nil
+}
+
+func runZonesImport(cmd *cobra.Command, args []string) error {
+	if zoneImportAs != "home" && !validZoneKind(zoneImportAs) {
+		return fmt.Errorf("invalid --as %q (expected %q, %q or %q)", zoneImportAs, models.ZoneExclude, models.ZonePrivacy, "home")
+	}
+
+	shapes, err := geojson.ParseFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", args[0], err)
+	}
+
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	for _, shape := range shapes {
+		if zoneImportAs == "home" {
+			if err := db.StoreHomeLocation(processor.AreaHome(shape.Name, shape.Area)); err != nil {
+				return fmt.Errorf("failed to store home location: %w", err)
+			}
+			fmt.Printf("✓ Imported home %s (%d points)\n", shape.Name, len(shape.Area[0]))
+			continue
+		}
+
+		id, err := db.StoreZone(models.Zone{Name: shape.Name, Kind: zoneImportAs, Area: shape.Area, CreatedAt: time.Now()})
+		if err != nil {
+			return fmt.Errorf("failed to store zone: %w", err)
+		}
+		fmt.Printf("✓ Imported %s zone %d: %s (%d points)\n", zoneImportAs, id, shape.Name, len(shape.Area[0]))
+	}
+
+	if zoneImportAs != models.ZonePrivacy {
+		fmt.Println("\nRun 'detect-trips' to apply the change")
+	}
+	return nil
+}
+
+func validZoneKind(kind string) bool {
+	return kind == models.ZoneExclude || kind == models.ZonePrivacy
+}
+
+func runZonesDelete(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid zone ID %q", arg)
+		}
+		if err := db.DeleteZone(id); err != nil {
+			return fmt.Errorf("failed to delete zone: %w", err)
+		}
+		fmt.Printf("✓ Deleted zone %d\n", id)
+	}
+	return nil
+}
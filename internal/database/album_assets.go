@@ -0,0 +1,54 @@
+package database
+
+// GetAlbumAssets returns the assets an album was last created or synced with. Albums
+// created before the journal have none.
+func (db *DB) GetAlbumAssets(albumID string) ([]string, error) {
+	rows, err := db.conn.Query(`SELECT asset_id FROM album_assets WHERE album_id = ? ORDER BY asset_id`, albumID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assetIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		assetIDs = append(assetIDs, id)
+	}
+	return assetIDs, rows.Err()
+}
+
+// StoreAlbumAssets replaces the journal of the assets an album was created or synced with
+func (db *DB) StoreAlbumAssets(albumID string, assetIDs []string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM album_assets WHERE album_id = ?", albumID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO album_assets (album_id, asset_id) VALUES (?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, id := range assetIDs {
+		if _, err := stmt.Exec(albumID, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteAlbumAssets removes the journal of an album that no longer exists
+func (db *DB) DeleteAlbumAssets(albumID string) error {
+	_, err := db.conn.Exec("DELETE FROM album_assets WHERE album_id = ?", albumID)
+	return err
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// GetClockOffsets retrieves all clock offsets ordered by device and start of range
+func (db *DB) GetClockOffsets() ([]models.ClockOffset, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, device_id, valid_from, valid_to, offset_seconds, confidence, matches, status
+		FROM clock_offsets
+		ORDER BY device_id, valid_from
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var offsets []models.ClockOffset
+	for rows.Next() {
+		var o models.ClockOffset
+		var validFrom, validTo sql.NullTime
+		err := rows.Scan(&o.ID, &o.DeviceID, &validFrom, &validTo,
+			&o.OffsetSeconds, &o.Confidence, &o.Matches, &o.Status)
+		if err != nil {
+			return nil, err
+		}
+		o.ValidFrom = validFrom.Time
+		o.ValidTo = validTo.Time
+		offsets = append(offsets, o)
+	}
+
+	return offsets, rows.Err()
+}
+
+// ReplaceSuggestedClockOffsets replaces all suggested offsets, keeping accepted and manual ones
+func (db *DB) ReplaceSuggestedClockOffsets(offsets []models.ClockOffset) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM clock_offsets WHERE status = ?`, models.ClockOffsetSuggested); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO clock_offsets (device_id, valid_from, valid_to, offset_seconds, confidence, matches, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, o := range offsets {
+		_, err := stmt.Exec(o.DeviceID, nullTime(o.ValidFrom), nullTime(o.ValidTo),
+			o.OffsetSeconds, o.Confidence, o.Matches, models.ClockOffsetSuggested)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// StoreClockOffset inserts a clock offset and returns its ID
+func (db *DB) StoreClockOffset(o models.ClockOffset) (int64, error) {
+	result, err := db.conn.Exec(`
+		INSERT INTO clock_offsets (device_id, valid_from, valid_to, offset_seconds, confidence, matches, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, o.DeviceID, nullTime(o.ValidFrom), nullTime(o.ValidTo), o.OffsetSeconds, o.Confidence, o.Matches, o.Status)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// UpdateClockOffsetStatus changes the status of a clock offset
+func (db *DB) UpdateClockOffsetStatus(id int64, status string) error {
+	result, err := db.conn.Exec(`UPDATE clock_offsets SET status = ? WHERE id = ?`, status, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("clock offset %d not found", id)
+	}
+	return nil
+}
+
+// DeleteClockOffset removes a clock offset by ID
+func (db *DB) DeleteClockOffset(id int64) error {
+	_, err := db.conn.Exec("DELETE FROM clock_offsets WHERE id = ?", id)
+	return err
+}
+
+// nullTime stores the zero time as NULL
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
@@ -2,7 +2,8 @@ package database
 
 import (
 	"database/sql"
-	"encoding/json"
+	"fmt"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/jamo/immich-albums/internal/models"
@@ -12,14 +13,32 @@ type DB struct {
 	conn *sql.DB
 }
 
+// Open opens the database and applies any pending schema migrations. It refuses to open a
+// database whose schema is newer than this binary.
 func Open(path string) (*DB, error) {
+	db, err := OpenUnmigrated(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// OpenUnmigrated opens the database without applying migrations, for inspecting its schema
+// version. It still refuses to open a database whose schema is newer than this binary.
+func OpenUnmigrated(path string) (*DB, error) {
 	conn, err := sql.Open("sqlite3", path)
 	if err != nil {
 		return nil, err
 	}
 
 	db := &DB{conn: conn}
-	if err := db.initSchema(); err != nil {
+	if err := db.checkSchemaVersion(); err != nil {
 		conn.Close()
 		return nil, err
 	}
@@ -31,145 +50,119 @@ func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
-func (db *DB) initSchema() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS assets (
-		id TEXT PRIMARY KEY,
-		device_asset_id TEXT,
-		owner_id TEXT,
-		device_id TEXT,
-		type TEXT,
-		original_path TEXT,
-		original_filename TEXT,
-		file_created_at TIMESTAMP,
-		file_modified_at TIMESTAMP,
-		local_datetime TIMESTAMP,
-		duration TEXT,
-		make TEXT,
-		model TEXT,
-		exif_image_width INTEGER,
-		exif_image_height INTEGER,
-		orientation TEXT,
-		lens_model TEXT,
-		f_number REAL,
-		focal_length REAL,
-		iso INTEGER,
-		exposure_time TEXT,
-		latitude REAL,
-		longitude REAL,
-		city TEXT,
-		state TEXT,
-		country TEXT,
-		inferred_latitude REAL,
-		inferred_longitude REAL,
-		location_confidence REAL,
-		location_source TEXT
-	);
-
-	CREATE TABLE IF NOT EXISTS devices (
-		id TEXT PRIMARY KEY,
-		make TEXT,
-		model TEXT,
-		photo_count INTEGER,
-		photographer TEXT
-	);
-
-	CREATE TABLE IF NOT EXISTS sessions (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		start_time TIMESTAMP,
-		end_time TIMESTAMP,
-		asset_ids TEXT,
-		center_lat REAL,
-		center_lon REAL,
-		radius REAL,
-		photographer TEXT
-	);
-
-	CREATE TABLE IF NOT EXISTS trips (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT,
-		start_time TIMESTAMP,
-		end_time TIMESTAMP,
-		home_distance REAL,
-		total_distance REAL,
-		center_lat REAL,
-		center_lon REAL,
-		asset_ids TEXT,
-		photographers TEXT,
-		session_count INTEGER,
-		album_id TEXT
-	);
-
-	CREATE TABLE IF NOT EXISTS home_locations (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT,
-		latitude REAL,
-		longitude REAL,
-		radius REAL
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_assets_datetime ON assets(local_datetime);
-	CREATE INDEX IF NOT EXISTS idx_assets_device ON assets(make, model);
-	CREATE INDEX IF NOT EXISTS idx_assets_location ON assets(latitude, longitude);
-	`
-
-	if _, err := db.conn.Exec(schema); err != nil {
-		return err
-	}
-
-	// Migration: Add album_id column to trips table if it doesn't exist
-	migrations := []string{
-		`ALTER TABLE trips ADD COLUMN album_id TEXT`,
-		`ALTER TABLE trips ADD COLUMN exclude_from_album INTEGER DEFAULT 0`,
-	}
-
-	for _, migration := range migrations {
-		// Ignore errors for migrations (column may already exist)
-		db.conn.Exec(migration)
-	}
-
-	return nil
+// AssetStoreStats reports how stored assets compared to what was already in the database
+type AssetStoreStats struct {
+	Added     int
+	Changed   int
+	Unchanged int
 }
 
-func (db *DB) StoreAssets(assets []models.Asset) error {
+// StoreAssets upserts assets, keeping inferred locations of existing rows.
+// Assets whose Immich modification time matches the stored row are left untouched.
+func (db *DB) StoreAssets(assets []models.Asset) (AssetStoreStats, error) {
+	var stats AssetStoreStats
+
 	tx, err := db.conn.Begin()
 	if err != nil {
-		return err
+		return stats, err
 	}
 	defer tx.Rollback()
 
+	existingStmt, err := tx.Prepare(`
+		SELECT updated_at, file_modified_at FROM assets WHERE id = ?
+	`)
+	if err != nil {
+		return stats, err
+	}
+	defer existingStmt.Close()
+
 	stmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO assets (
+		INSERT INTO assets (
 			id, device_asset_id, owner_id, device_id, type, original_path, original_filename,
-			file_created_at, file_modified_at, local_datetime, duration,
+			file_created_at, file_modified_at, local_datetime, updated_at, duration,
 			make, model, exif_image_width, exif_image_height, orientation, lens_model,
 			f_number, focal_length, iso, exposure_time,
 			latitude, longitude, city, state, country
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			device_asset_id = excluded.device_asset_id,
+			owner_id = excluded.owner_id,
+			device_id = excluded.device_id,
+			type = excluded.type,
+			original_path = excluded.original_path,
+			original_filename = excluded.original_filename,
+			file_created_at = excluded.file_created_at,
+			file_modified_at = excluded.file_modified_at,
+			local_datetime = excluded.local_datetime,
+			updated_at = excluded.updated_at,
+			duration = excluded.duration,
+			make = excluded.make,
+			model = excluded.model,
+			exif_image_width = excluded.exif_image_width,
+			exif_image_height = excluded.exif_image_height,
+			orientation = excluded.orientation,
+			lens_model = excluded.lens_model,
+			f_number = excluded.f_number,
+			focal_length = excluded.focal_length,
+			iso = excluded.iso,
+			exposure_time = excluded.exposure_time,
+			latitude = excluded.latitude,
+			longitude = excluded.longitude,
+			city = excluded.city,
+			state = excluded.state,
+			country = excluded.country
 	`)
 	if err != nil {
-		return err
+		return stats, err
 	}
 	defer stmt.Close()
 
 	for _, asset := range assets {
-		_, err := stmt.Exec(
+		var updatedAt, fileModifiedAt sql.NullTime
+		err := existingStmt.QueryRow(asset.ID).Scan(&updatedAt, &fileModifiedAt)
+		switch {
+		case err == sql.ErrNoRows:
+			stats.Added++
+		case err != nil:
+			return stats, err
+		case sameTime(updatedAt, asset.UpdatedAt) && sameTime(fileModifiedAt, asset.FileModifiedAt):
+			stats.Unchanged++
+			continue
+		default:
+			stats.Changed++
+		}
+
+		var updated interface{}
+		if !asset.UpdatedAt.IsZero() {
+			updated = asset.UpdatedAt
+		}
+
+		_, err = stmt.Exec(
 			asset.ID, asset.DeviceAssetID, asset.OwnerID, asset.DeviceID, asset.Type,
 			asset.OriginalPath, asset.OriginalFileName,
-			asset.FileCreatedAt, asset.FileModifiedAt, asset.LocalDateTime, asset.Duration,
+			asset.FileCreatedAt, asset.FileModifiedAt, asset.LocalDateTime, updated, asset.Duration,
 			asset.Make, asset.Model, asset.ExifImageWidth, asset.ExifImageHeight,
 			asset.Orientation, asset.LensModel, asset.FNumber, asset.FocalLength,
 			asset.ISO, asset.ExposureTime,
 			asset.Latitude, asset.Longitude, asset.City, asset.State, asset.Country,
 		)
 		if err != nil {
-			return err
+			return stats, err
 		}
 	}
 
-	return tx.Commit()
+	return stats, tx.Commit()
+}
+
+// sameTime reports whether a stored timestamp matches t, treating NULL as the zero time
+func sameTime(stored sql.NullTime, t time.Time) bool {
+	if !stored.Valid {
+		return t.IsZero()
+	}
+	return stored.Time.Equal(t)
 }
 
+// StoreDevices upserts devices. An empty photographer never overwrites an existing label.
 func (db *DB) StoreDevices(devices []models.Device) error {
 	tx, err := db.conn.Begin()
 	if err != nil {
@@ -178,8 +171,15 @@ func (db *DB) StoreDevices(devices []models.Device) error {
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO devices (id, make, model, photo_count, photographer)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO devices (id, make, model, photo_count, photographer, counter_min, counter_max)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			make = excluded.make,
+			model = excluded.model,
+			photo_count = excluded.photo_count,
+			photographer = COALESCE(NULLIF(excluded.photographer, ''), devices.photographer),
+			counter_min = excluded.counter_min,
+			counter_max = excluded.counter_max
 	`)
 	if err != nil {
 		return err
@@ -187,9 +187,16 @@ func (db *DB) StoreDevices(devices []models.Device) error {
 	defer stmt.Close()
 
 	for _, device := range devices {
+		var counterMin, counterMax interface{}
+		if device.CounterRange != nil {
+			counterMin = device.CounterRange.Min
+			counterMax = device.CounterRange.Max
+		}
+
 		_, err := stmt.Exec(
 			device.ID, device.Make, device.Model,
 			device.PhotoCount, device.Photographer,
+			counterMin, counterMax,
 		)
 		if err != nil {
 			return err
@@ -201,7 +208,7 @@ func (db *DB) StoreDevices(devices []models.Device) error {
 
 func (db *DB) GetDevices() ([]models.Device, error) {
 	rows, err := db.conn.Query(`
-		SELECT id, make, model, photo_count, photographer
+		SELECT id, make, model, photo_count, photographer, counter_min, counter_max
 		FROM devices
 		ORDER BY photo_count DESC
 	`)
@@ -214,13 +221,20 @@ func (db *DB) GetDevices() ([]models.Device, error) {
 	for rows.Next() {
 		var d models.Device
 		var photographer sql.NullString
-		err := rows.Scan(&d.ID, &d.Make, &d.Model, &d.PhotoCount, &photographer)
+		var counterMin, counterMax sql.NullInt64
+		err := rows.Scan(&d.ID, &d.Make, &d.Model, &d.PhotoCount, &photographer, &counterMin, &counterMax)
 		if err != nil {
 			return nil, err
 		}
 		if photographer.Valid {
 			d.Photographer = photographer.String
 		}
+		if counterMin.Valid && counterMax.Valid {
+			d.CounterRange = &models.CounterRange{
+				Min: int(counterMin.Int64),
+				Max: int(counterMax.Int64),
+			}
+		}
 		devices = append(devices, d)
 	}
 
@@ -237,11 +251,12 @@ func (db *DB) UpdateDevicePhotographer(deviceID, photographer string) error {
 func (db *DB) GetAssets() ([]models.Asset, error) {
 	rows, err := db.conn.Query(`
 		SELECT id, device_asset_id, owner_id, device_id, type, original_path, original_filename,
-			file_created_at, file_modified_at, local_datetime, duration,
+			file_created_at, file_modified_at, local_datetime, updated_at, duration,
 			make, model, exif_image_width, exif_image_height, orientation, lens_model,
 			f_number, focal_length, iso, exposure_time,
 			latitude, longitude, city, state, country,
-			inferred_latitude, inferred_longitude, location_confidence, location_source
+			inferred_latitude, inferred_longitude, location_confidence, location_source,
+			device_key
 		FROM assets
 		ORDER BY local_datetime
 	`)
@@ -254,22 +269,28 @@ func (db *DB) GetAssets() ([]models.Asset, error) {
 	for rows.Next() {
 		var a models.Asset
 		var lat, lon, inferredLat, inferredLon, confidence sql.NullFloat64
-		var locationSource sql.NullString
+		var locationSource, deviceKey sql.NullString
+		var updatedAt sql.NullTime
 
 		err := rows.Scan(
 			&a.ID, &a.DeviceAssetID, &a.OwnerID, &a.DeviceID, &a.Type,
 			&a.OriginalPath, &a.OriginalFileName,
-			&a.FileCreatedAt, &a.FileModifiedAt, &a.LocalDateTime, &a.Duration,
+			&a.FileCreatedAt, &a.FileModifiedAt, &a.LocalDateTime, &updatedAt, &a.Duration,
 			&a.Make, &a.Model, &a.ExifImageWidth, &a.ExifImageHeight,
 			&a.Orientation, &a.LensModel, &a.FNumber, &a.FocalLength,
 			&a.ISO, &a.ExposureTime,
 			&lat, &lon, &a.City, &a.State, &a.Country,
 			&inferredLat, &inferredLon, &confidence, &locationSource,
+			&deviceKey,
 		)
 		if err != nil {
 			return nil, err
 		}
 
+		if updatedAt.Valid {
+			a.UpdatedAt = updatedAt.Time
+		}
+		a.DeviceKey = deviceKey.String
 		if lat.Valid {
 			a.Latitude = &lat.Float64
 		}
@@ -283,20 +304,76 @@ func (db *DB) GetAssets() ([]models.Asset, error) {
 	return assets, nil
 }
 
-func (db *DB) StoreSessions(sessions []models.Session) error {
-	// Clear existing sessions first
-	if _, err := db.conn.Exec("DELETE FROM sessions"); err != nil {
+// UpdateAssetDeviceKeys stores the resolved device ID of each asset.
+// Assets missing from the map get their device key cleared.
+func (db *DB) UpdateAssetDeviceKeys(assignments map[string]string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE assets SET device_key = NULL`); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`UPDATE assets SET device_key = ? WHERE id = ?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for assetID, deviceID := range assignments {
+		if _, err := stmt.Exec(deviceID, assetID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteAssets removes assets by ID
+func (db *DB) DeleteAssets(ids []string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`DELETE FROM assets WHERE id = ?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, id := range ids {
+		if _, err := stmt.Exec(id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
 
+// StoreSessions replaces all sessions. Sessions with an ID keep it, so trips stay linked
+// to them; new sessions get a fresh ID. Trip links to sessions that no longer exist are removed.
+func (db *DB) StoreSessions(sessions []models.Session) error {
 	tx, err := db.conn.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	// Clear existing sessions first
+	if _, err := tx.Exec("DELETE FROM sessions"); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM session_assets"); err != nil {
+		return err
+	}
+
 	stmt, err := tx.Prepare(`
-		INSERT INTO sessions (start_time, end_time, asset_ids, center_lat, center_lon, radius, photographer)
+		INSERT INTO sessions (id, start_time, end_time, center_lat, center_lon, radius, photographer)
 		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
@@ -305,14 +382,34 @@ func (db *DB) StoreSessions(sessions []models.Session) error {
 	defer stmt.Close()
 
 	for _, session := range sessions {
-		assetIDs, _ := json.Marshal(session.AssetIDs)
-		_, err := stmt.Exec(
-			session.StartTime, session.EndTime, string(assetIDs),
+		var id interface{}
+		if session.ID != 0 {
+			id = session.ID
+		}
+		result, err := stmt.Exec(
+			id, session.StartTime, session.EndTime,
 			session.CenterLat, session.CenterLon, session.Radius, session.Photographer,
 		)
 		if err != nil {
 			return err
 		}
+		sessionID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		if err := insertSessionAssets(tx, sessionID, session.AssetIDs); err != nil {
+			return err
+		}
+		for _, attachment := range session.Attachments {
+			if _, err := tx.Exec(`UPDATE session_assets SET reason = ? WHERE session_id = ? AND asset_id = ?`,
+				attachment.Reason, sessionID, attachment.AssetID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM trip_sessions WHERE session_id NOT IN (SELECT id FROM sessions)`); err != nil {
+		return err
 	}
 
 	return tx.Commit()
@@ -320,7 +417,7 @@ func (db *DB) StoreSessions(sessions []models.Session) error {
 
 func (db *DB) GetSessions() ([]models.Session, error) {
 	rows, err := db.conn.Query(`
-		SELECT id, start_time, end_time, asset_ids, center_lat, center_lon, radius, photographer
+		SELECT id, start_time, end_time, center_lat, center_lon, radius, photographer
 		FROM sessions
 		ORDER BY start_time
 	`)
@@ -332,30 +429,86 @@ func (db *DB) GetSessions() ([]models.Session, error) {
 	var sessions []models.Session
 	for rows.Next() {
 		var s models.Session
-		var assetIDsJSON string
-		err := rows.Scan(&s.ID, &s.StartTime, &s.EndTime, &assetIDsJSON,
+		err := rows.Scan(&s.ID, &s.StartTime, &s.EndTime,
 			&s.CenterLat, &s.CenterLon, &s.Radius, &s.Photographer)
 		if err != nil {
 			return nil, err
 		}
-		json.Unmarshal([]byte(assetIDsJSON), &s.AssetIDs)
 		sessions = append(sessions, s)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	assetIDs, err := db.getLinkedAssetIDs(`SELECT session_id, asset_id FROM session_assets ORDER BY session_id, position`)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].AssetIDs = assetIDs[sessions[i].ID]
+	}
+
+	attachments, err := db.getSessionAttachments()
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Attachments = attachments[sessions[i].ID]
+	}
 
 	return sessions, nil
 }
 
+// getSessionAttachments returns the assets attached to sessions by time, grouped by session
+func (db *DB) getSessionAttachments() (map[int64][]models.SessionAttachment, error) {
+	rows, err := db.conn.Query(`
+		SELECT session_id, asset_id, reason FROM session_assets
+		WHERE reason IS NOT NULL
+		ORDER BY session_id, position
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := make(map[int64][]models.SessionAttachment)
+	for rows.Next() {
+		var sessionID int64
+		var a models.SessionAttachment
+		if err := rows.Scan(&sessionID, &a.AssetID, &a.Reason); err != nil {
+			return nil, err
+		}
+		attachments[sessionID] = append(attachments[sessionID], a)
+	}
+	return attachments, rows.Err()
+}
+
+// GetSession retrieves a single session by ID
+func (db *DB) GetSession(id int64) (*models.Session, error) {
+	sessions, err := db.GetSessions()
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		if sessions[i].ID == id {
+			return &sessions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("session %d not found", id)
+}
+
 func (db *DB) StoreHomeLocation(home models.HomeLocation) error {
 	_, err := db.conn.Exec(`
-		INSERT INTO home_locations (name, latitude, longitude, radius)
-		VALUES (?, ?, ?, ?)
-	`, home.Name, home.Latitude, home.Longitude, home.Radius)
+		INSERT INTO home_locations (name, latitude, longitude, radius, area, valid_from, valid_to, photographer)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, home.Name, home.Latitude, home.Longitude, home.Radius, nullPolygon(home.Area),
+		nullTime(home.ValidFrom), nullTime(home.ValidTo), home.Photographer)
 	return err
 }
 
 func (db *DB) GetHomeLocations() ([]models.HomeLocation, error) {
 	rows, err := db.conn.Query(`
-		SELECT id, name, latitude, longitude, radius
+		SELECT id, name, latitude, longitude, radius, area, valid_from, valid_to, COALESCE(photographer, '')
 		FROM home_locations
 	`)
 	if err != nil {
@@ -366,9 +519,16 @@ func (db *DB) GetHomeLocations() ([]models.HomeLocation, error) {
 	var homes []models.HomeLocation
 	for rows.Next() {
 		var h models.HomeLocation
-		if err := rows.Scan(&h.ID, &h.Name, &h.Latitude, &h.Longitude, &h.Radius); err != nil {
+		var area sql.NullString
+		var validFrom, validTo sql.NullTime
+		if err := rows.Scan(&h.ID, &h.Name, &h.Latitude, &h.Longitude, &h.Radius, &area, &validFrom, &validTo, &h.Photographer); err != nil {
 			return nil, err
 		}
+		if h.Area, err = scanPolygon(area); err != nil {
+			return nil, fmt.Errorf("invalid area for home location %d: %w", h.ID, err)
+		}
+		h.ValidFrom = validFrom.Time
+		h.ValidTo = validTo.Time
 		homes = append(homes, h)
 	}
 
@@ -1,5 +1,28 @@
 package database
 
+import (
+	"fmt"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// UpdateHomeLocation updates a home location's details, date range and photographer
+func (db *DB) UpdateHomeLocation(home models.HomeLocation) error {
+	result, err := db.conn.Exec(`
+		UPDATE home_locations
+		SET name = ?, latitude = ?, longitude = ?, radius = ?, area = ?, valid_from = ?, valid_to = ?, photographer = ?
+		WHERE id = ?
+	`, home.Name, home.Latitude, home.Longitude, home.Radius, nullPolygon(home.Area),
+		nullTime(home.ValidFrom), nullTime(home.ValidTo), home.Photographer, home.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("home location %d not found", home.ID)
+	}
+	return nil
+}
+
 // DeleteHomeLocation removes a home location by ID
 func (db *DB) DeleteHomeLocation(id int64) error {
 	_, err := db.conn.Exec("DELETE FROM home_locations WHERE id = ?", id)
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// GetInferredLocations loads stored location inferences keyed by asset ID
+func (db *DB) GetInferredLocations() (map[string]models.LocationInference, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, inferred_latitude, inferred_longitude, location_confidence, location_source
+		FROM assets
+		WHERE inferred_latitude IS NOT NULL AND inferred_longitude IS NOT NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inferences := make(map[string]models.LocationInference)
+	for rows.Next() {
+		var inf models.LocationInference
+		var confidence sql.NullFloat64
+		var source sql.NullString
+
+		if err := rows.Scan(&inf.AssetID, &inf.Latitude, &inf.Longitude, &confidence, &source); err != nil {
+			return nil, err
+		}
+
+		inf.Confidence = confidence.Float64
+		inf.Source = source.String
+		inferences[inf.AssetID] = inf
+	}
+
+	return inferences, rows.Err()
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// insertSessionAssets links assets to a session, keeping their order
+func insertSessionAssets(tx *sql.Tx, sessionID int64, assetIDs []string) error {
+	return insertLinks(tx, `INSERT OR IGNORE INTO session_assets (session_id, asset_id, position) VALUES (?, ?, ?)`, sessionID, assetIDs)
+}
+
+// insertTripAssets links assets to a trip, keeping their order
+func insertTripAssets(tx *sql.Tx, tripID int64, assetIDs []string) error {
+	return insertLinks(tx, `INSERT OR IGNORE INTO trip_assets (trip_id, asset_id, position) VALUES (?, ?, ?)`, tripID, assetIDs)
+}
+
+// insertTripSessions links sessions to a trip, keeping their order
+func insertTripSessions(tx *sql.Tx, tripID int64, sessionIDs []int64) error {
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO trip_sessions (trip_id, session_id, position) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, sessionID := range sessionIDs {
+		if _, err := stmt.Exec(tripID, sessionID, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertLinks(tx *sql.Tx, query string, ownerID int64, assetIDs []string) error {
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, assetID := range assetIDs {
+		if _, err := stmt.Exec(ownerID, assetID, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceTripLinks rewrites the assets and sessions linked to a trip
+func replaceTripLinks(tx *sql.Tx, trip *models.Trip) error {
+	if _, err := tx.Exec("DELETE FROM trip_assets WHERE trip_id = ?", trip.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM trip_sessions WHERE trip_id = ?", trip.ID); err != nil {
+		return err
+	}
+
+	if err := insertTripAssets(tx, trip.ID, trip.AssetIDs); err != nil {
+		return err
+	}
+	sessionIDs := make([]int64, 0, len(trip.Sessions))
+	for _, session := range trip.Sessions {
+		if session.ID != 0 {
+			sessionIDs = append(sessionIDs, session.ID)
+		}
+	}
+	return insertTripSessions(tx, trip.ID, sessionIDs)
+}
+
+// getLinkedAssetIDs runs a query returning (owner ID, asset ID) rows and groups the asset IDs by owner
+func (db *DB) getLinkedAssetIDs(query string, args ...interface{}) (map[int64][]string, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	linked := make(map[int64][]string)
+	for rows.Next() {
+		var ownerID int64
+		var assetID string
+		if err := rows.Scan(&ownerID, &assetID); err != nil {
+			return nil, err
+		}
+		linked[ownerID] = append(linked[ownerID], assetID)
+	}
+	return linked, rows.Err()
+}
+
+// attachTripLinks loads the assets and sessions of the given trips
+func (db *DB) attachTripLinks(trips []models.Trip) error {
+	if len(trips) == 0 {
+		return nil
+	}
+
+	query, args := `SELECT trip_id, asset_id FROM trip_assets ORDER BY trip_id, position`, []interface{}(nil)
+	sessionQuery := `SELECT trip_id, session_id FROM trip_sessions ORDER BY trip_id, position`
+	if len(trips) == 1 {
+		query = `SELECT trip_id, asset_id FROM trip_assets WHERE trip_id = ? ORDER BY position`
+		sessionQuery = `SELECT trip_id, session_id FROM trip_sessions WHERE trip_id = ? ORDER BY position`
+		args = []interface{}{trips[0].ID}
+	}
+
+	assetIDs, err := db.getLinkedAssetIDs(query, args...)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := db.GetSessions()
+	if err != nil {
+		return err
+	}
+	sessionsByID := make(map[int64]models.Session, len(sessions))
+	for _, session := range sessions {
+		sessionsByID[session.ID] = session
+	}
+
+	rows, err := db.conn.Query(sessionQuery, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	tripSessions := make(map[int64][]models.Session)
+	for rows.Next() {
+		var tripID, sessionID int64
+		if err := rows.Scan(&tripID, &sessionID); err != nil {
+			return err
+		}
+		if session, ok := sessionsByID[sessionID]; ok {
+			tripSessions[tripID] = append(tripSessions[tripID], session)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range trips {
+		trips[i].AssetIDs = assetIDs[trips[i].ID]
+		trips[i].Sessions = tripSessions[trips[i].ID]
+	}
+	return nil
+}
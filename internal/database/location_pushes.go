@@ -0,0 +1,121 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// LocationPush is a journal entry for an inferred location written to Immich
+type LocationPush struct {
+	AssetID           string
+	OriginalLatitude  *float64 // Location in Immich before the first push, nil if none
+	OriginalLongitude *float64
+	PushedLatitude    float64
+	PushedLongitude   float64
+	Confidence        float64
+	PushedAt          time.Time
+	RevertedAt        time.Time // Zero while the pushed location is active
+}
+
+// Active reports whether the pushed location is still in effect
+func (p LocationPush) Active() bool {
+	return p.RevertedAt.IsZero()
+}
+
+// GetLocationPushes retrieves the push journal keyed by asset ID
+func (db *DB) GetLocationPushes() (map[string]LocationPush, error) {
+	rows, err := db.conn.Query(`
+		SELECT asset_id, original_latitude, original_longitude, pushed_latitude, pushed_longitude,
+			confidence, pushed_at, reverted_at
+		FROM location_pushes
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pushes := make(map[string]LocationPush)
+	for rows.Next() {
+		var p LocationPush
+		var originalLat, originalLon sql.NullFloat64
+		var pushedAt, revertedAt sql.NullTime
+		err := rows.Scan(&p.AssetID, &originalLat, &originalLon, &p.PushedLatitude, &p.PushedLongitude,
+			&p.Confidence, &pushedAt, &revertedAt)
+		if err != nil {
+			return nil, err
+		}
+		if originalLat.Valid && originalLon.Valid {
+			p.OriginalLatitude = &originalLat.Float64
+			p.OriginalLongitude = &originalLon.Float64
+		}
+		p.PushedAt = pushedAt.Time
+		p.RevertedAt = revertedAt.Time
+		pushes[p.AssetID] = p
+	}
+
+	return pushes, rows.Err()
+}
+
+// StoreLocationPushes inserts or replaces journal entries
+func (db *DB) StoreLocationPushes(pushes []LocationPush) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO location_pushes (
+			asset_id, original_latitude, original_longitude, pushed_latitude, pushed_longitude,
+			confidence, pushed_at, reverted_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, p := range pushes {
+		_, err := stmt.Exec(p.AssetID, p.OriginalLatitude, p.OriginalLongitude, p.PushedLatitude, p.PushedLongitude,
+			p.Confidence, p.PushedAt, nullTime(p.RevertedAt))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteLocationPushes removes journal entries
+func (db *DB) DeleteLocationPushes(assetIDs []string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range assetIDs {
+		if _, err := tx.Exec("DELETE FROM location_pushes WHERE asset_id = ?", id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// MarkLocationPushesReverted records that pushed locations were reverted in Immich
+func (db *DB) MarkLocationPushesReverted(assetIDs []string, revertedAt time.Time) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range assetIDs {
+		if _, err := tx.Exec("UPDATE location_pushes SET reverted_at = ? WHERE asset_id = ?", revertedAt, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,635 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// migration is one step of the schema. Migrations run in order, each in its own
+// transaction, and are recorded in schema_version. Never edit or reorder a released
+// migration; append a new one instead.
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// MigrationStatus describes a schema migration and whether it has been applied
+type MigrationStatus struct {
+	Version     int
+	Description string
+	AppliedAt   time.Time // Zero if pending
+}
+
+var migrations = []migration{
+	{1, "Initial schema", execMigration(`
+		CREATE TABLE IF NOT EXISTS assets (
+			id TEXT PRIMARY KEY,
+			device_asset_id TEXT,
+			owner_id TEXT,
+			device_id TEXT,
+			type TEXT,
+			original_path TEXT,
+			original_filename TEXT,
+			file_created_at TIMESTAMP,
+			file_modified_at TIMESTAMP,
+			local_datetime TIMESTAMP,
+			duration TEXT,
+			make TEXT,
+			model TEXT,
+			exif_image_width INTEGER,
+			exif_image_height INTEGER,
+			orientation TEXT,
+			lens_model TEXT,
+			f_number REAL,
+			focal_length REAL,
+			iso INTEGER,
+			exposure_time TEXT,
+			latitude REAL,
+			longitude REAL,
+			city TEXT,
+			state TEXT,
+			country TEXT,
+			inferred_latitude REAL,
+			inferred_longitude REAL,
+			location_confidence REAL,
+			location_source TEXT
+		);
+
+		CREATE TABLE IF NOT EXISTS devices (
+			id TEXT PRIMARY KEY,
+			make TEXT,
+			model TEXT,
+			photo_count INTEGER,
+			photographer TEXT
+		);
+
+		CREATE TABLE IF NOT EXISTS sessions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			start_time TIMESTAMP,
+			end_time TIMESTAMP,
+			asset_ids TEXT,
+			center_lat REAL,
+			center_lon REAL,
+			radius REAL,
+			photographer TEXT
+		);
+
+		CREATE TABLE IF NOT EXISTS trips (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT,
+			start_time TIMESTAMP,
+			end_time TIMESTAMP,
+			home_distance REAL,
+			total_distance REAL,
+			center_lat REAL,
+			center_lon REAL,
+			asset_ids TEXT,
+			photographers TEXT,
+			session_count INTEGER
+		);
+
+		CREATE TABLE IF NOT EXISTS home_locations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT,
+			latitude REAL,
+			longitude REAL,
+			radius REAL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_assets_datetime ON assets(local_datetime);
+		CREATE INDEX IF NOT EXISTS idx_assets_device ON assets(make, model);
+		CREATE INDEX IF NOT EXISTS idx_assets_location ON assets(latitude, longitude);
+	`)},
+	{2, "Trip album IDs and exclusions", func(tx *sql.Tx) error {
+		if err := addColumn(tx, "trips", "album_id", "TEXT"); err != nil {
+			return err
+		}
+		return addColumn(tx, "trips", "exclude_from_album", "INTEGER DEFAULT 0")
+	}},
+	{3, "Incremental sync state", func(tx *sql.Tx) error {
+		if err := addColumn(tx, "assets", "updated_at", "TIMESTAMP"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS sync_state (
+				scope TEXT PRIMARY KEY,
+				last_updated_at TIMESTAMP,
+				last_synced_at TIMESTAMP
+			)
+		`)
+		return err
+	}},
+	{4, "Device counter ranges", func(tx *sql.Tx) error {
+		if err := addColumn(tx, "devices", "counter_min", "INTEGER"); err != nil {
+			return err
+		}
+		return addColumn(tx, "devices", "counter_max", "INTEGER")
+	}},
+	{5, "Resolved asset device keys", func(tx *sql.Tx) error {
+		if err := addColumn(tx, "assets", "device_key", "TEXT"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_assets_device_key ON assets(device_key)`)
+		return err
+	}},
+	{6, "Camera clock offsets", execMigration(`
+		CREATE TABLE IF NOT EXISTS clock_offsets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			device_id TEXT,
+			valid_from TIMESTAMP,
+			valid_to TIMESTAMP,
+			offset_seconds INTEGER,
+			confidence REAL,
+			matches INTEGER,
+			status TEXT
+		)
+	`)},
+	{7, "Imported track points", execMigration(`
+		CREATE TABLE IF NOT EXISTS track_points (
+			photographer TEXT,
+			time TIMESTAMP,
+			latitude REAL,
+			longitude REAL,
+			source TEXT,
+			PRIMARY KEY (photographer, time)
+		)
+	`)},
+	{8, "Location push journal", execMigration(`
+		CREATE TABLE IF NOT EXISTS location_pushes (
+			asset_id TEXT PRIMARY KEY,
+			original_latitude REAL,
+			original_longitude REAL,
+			pushed_latitude REAL,
+			pushed_longitude REAL,
+			confidence REAL,
+			pushed_at TIMESTAMP,
+			reverted_at TIMESTAMP
+		)
+	`)},
+	{9, "Session and trip membership tables", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS session_assets (
+				session_id INTEGER NOT NULL,
+				asset_id TEXT NOT NULL,
+				position INTEGER,
+				PRIMARY KEY (session_id, asset_id)
+			);
+
+			CREATE TABLE IF NOT EXISTS trip_sessions (
+				trip_id INTEGER NOT NULL,
+				session_id INTEGER NOT NULL,
+				position INTEGER,
+				PRIMARY KEY (trip_id, session_id)
+			);
+
+			CREATE TABLE IF NOT EXISTS trip_assets (
+				trip_id INTEGER NOT NULL,
+				asset_id TEXT NOT NULL,
+				position INTEGER,
+				PRIMARY KEY (trip_id, asset_id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_session_assets_asset ON session_assets(asset_id);
+			CREATE INDEX IF NOT EXISTS idx_trip_sessions_session ON trip_sessions(session_id);
+			CREATE INDEX IF NOT EXISTS idx_trip_assets_asset ON trip_assets(asset_id);
+		`)
+		if err != nil {
+			return err
+		}
+		return migrateAssetIDBlobs(tx)
+	}},
+	{10, "Custom trip names", func(tx *sql.Tx) error {
+		return addColumn(tx, "trips", "custom_name", "INTEGER DEFAULT 0")
+	}},
+	{11, "Manual trip rules", execMigration(`
+		CREATE TABLE IF NOT EXISTS trip_rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL,
+			asset_id TEXT,
+			target_asset_id TEXT,
+			time TIMESTAMP,
+			description TEXT,
+			created_at TIMESTAMP
+		)
+	`)},
+	{12, "Home location date ranges", func(tx *sql.Tx) error {
+		if err := addColumn(tx, "home_locations", "valid_from", "TIMESTAMP"); err != nil {
+			return err
+		}
+		return addColumn(tx, "home_locations", "valid_to", "TIMESTAMP")
+	}},
+	{13, "Per-photographer home locations", func(tx *sql.Tx) error {
+		return addColumn(tx, "home_locations", "photographer", "TEXT")
+	}},
+	{14, "Polygon home areas and zones", func(tx *sql.Tx) error {
+		if err := addColumn(tx, "home_locations", "area", "TEXT"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS zones (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL,
+				kind TEXT NOT NULL,
+				area TEXT,
+				latitude REAL,
+				longitude REAL,
+				radius REAL,
+				created_at TIMESTAMP
+			)
+		`)
+		return err
+	}},
+	{15, "Circular zones", func(tx *sql.Tx) error {
+		for _, column := range []string{"latitude", "longitude", "radius"} {
+			if err := addColumn(tx, "zones", column, "REAL"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{16, "Session attachment reasons", func(tx *sql.Tx) error {
+		return addColumn(tx, "session_assets", "reason", "TEXT")
+	}},
+	{17, "Trip route summaries", func(tx *sql.Tx) error {
+		return addColumn(tx, "trips", "summary", "TEXT")
+	}},
+	{18, "Album asset journal", execMigration(`
+		CREATE TABLE IF NOT EXISTS album_assets (
+			album_id TEXT NOT NULL,
+			asset_id TEXT NOT NULL,
+			PRIMARY KEY (album_id, asset_id)
+		)
+	`)},
+	{19, "Legacy custom trip names", markCustomTripNames},
+}
+
+// LatestSchemaVersion is the schema version this binary migrates databases to
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// Migrate applies pending migrations in order and returns the ones it applied.
+// Each migration runs in its own transaction, so a failure leaves the database at the
+// last successfully applied version.
+func (db *DB) Migrate() ([]MigrationStatus, error) {
+	if _, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			description TEXT,
+			applied_at TIMESTAMP
+		)
+	`); err != nil {
+		return nil, fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []MigrationStatus
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		appliedAt := time.Now()
+		if err := db.applyMigration(m, appliedAt); err != nil {
+			return applied, fmt.Errorf("migration %d (%s) failed: %w", m.version, m.description, err)
+		}
+		applied = append(applied, MigrationStatus{Version: m.version, Description: m.description, AppliedAt: appliedAt})
+	}
+
+	return applied, nil
+}
+
+func (db *DB) applyMigration(m migration, appliedAt time.Time) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO schema_version (version, description, applied_at) VALUES (?, ?, ?)
+	`, m.version, m.description, appliedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SchemaVersion returns the version of the database schema, 0 if it has never been migrated
+func (db *DB) SchemaVersion() (int, error) {
+	var tables int
+	if err := db.conn.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'
+	`).Scan(&tables); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if tables == 0 {
+		return 0, nil
+	}
+
+	var version int
+	if err := db.conn.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// SchemaStatus lists all migrations known to this binary with their applied time
+func (db *DB) SchemaStatus() ([]MigrationStatus, error) {
+	appliedAt := make(map[int]time.Time)
+
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if current > 0 {
+		rows, err := db.conn.Query(`SELECT version, applied_at FROM schema_version`)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var version int
+			var at time.Time
+			if err := rows.Scan(&version, &at); err != nil {
+				return nil, err
+			}
+			appliedAt[version] = at
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		statuses = append(statuses, MigrationStatus{Version: m.version, Description: m.description, AppliedAt: appliedAt[m.version]})
+	}
+	return statuses, nil
+}
+
+// checkSchemaVersion refuses databases written by a newer binary
+func (db *DB) checkSchemaVersion() error {
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if latest := LatestSchemaVersion(); current > latest {
+		return fmt.Errorf("database schema version %d is newer than this binary supports (%d); upgrade immich-albums", current, latest)
+	}
+	return nil
+}
+
+// execMigration returns a migration step that executes fixed SQL
+func execMigration(query string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(query)
+		return err
+	}
+}
+
+// hasColumn reports whether a table has a column
+func hasColumn(tx *sql.Tx, table, column string) (bool, error) {
+	var count int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	return count > 0, err
+}
+
+// addColumn adds a column unless it already exists. Databases created before schema
+// versioning may have some of the columns added by early migrations.
+func addColumn(tx *sql.Tx, table, column, definition string) error {
+	exists, err := hasColumn(tx, table, column)
+	if err != nil || exists {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// migrateAssetIDBlobs moves the JSON asset_ids columns of sessions and trips into the
+// session_assets, trip_assets and trip_sessions tables, then drops the old columns
+func migrateAssetIDBlobs(tx *sql.Tx) error {
+	hasBlobs, err := hasColumn(tx, "sessions", "asset_ids")
+	if err != nil || !hasBlobs {
+		return err
+	}
+
+	// Sessions, in time order so trip sessions are linked in order
+	rows, err := tx.Query(`SELECT id, COALESCE(asset_ids, '[]') FROM sessions ORDER BY start_time`)
+	if err != nil {
+		return err
+	}
+	sessionAssets := make(map[int64][]string)
+	var sessionIDs []int64
+	for rows.Next() {
+		var id int64
+		var assetIDsJSON string
+		if err := rows.Scan(&id, &assetIDsJSON); err != nil {
+			rows.Close()
+			return err
+		}
+		var assetIDs []string
+		json.Unmarshal([]byte(assetIDsJSON), &assetIDs)
+		sessionAssets[id] = assetIDs
+		sessionIDs = append(sessionIDs, id)
+	}
+	rows.Close()
+
+	assetSession := make(map[string]int64)
+	for _, id := range sessionIDs {
+		if err := insertSessionAssets(tx, id, sessionAssets[id]); err != nil {
+			return err
+		}
+		for _, assetID := range sessionAssets[id] {
+			assetSession[assetID] = id
+		}
+	}
+
+	// Trips, linking each to the sessions its assets came from
+	rows, err = tx.Query(`SELECT id, COALESCE(asset_ids, '[]') FROM trips`)
+	if err != nil {
+		return err
+	}
+	tripAssets := make(map[int64][]string)
+	for rows.Next() {
+		var id int64
+		var assetIDsJSON string
+		if err := rows.Scan(&id, &assetIDsJSON); err != nil {
+			rows.Close()
+			return err
+		}
+		var assetIDs []string
+		json.Unmarshal([]byte(assetIDsJSON), &assetIDs)
+		tripAssets[id] = assetIDs
+	}
+	rows.Close()
+
+	sessionOrder := make(map[int64]int, len(sessionIDs))
+	for i, id := range sessionIDs {
+		sessionOrder[id] = i
+	}
+	for tripID, assetIDs := range tripAssets {
+		if err := insertTripAssets(tx, tripID, assetIDs); err != nil {
+			return err
+		}
+
+		linked := make(map[int64]bool)
+		var tripSessionIDs []int64
+		for _, assetID := range assetIDs {
+			if sessionID, ok := assetSession[assetID]; ok && !linked[sessionID] {
+				linked[sessionID] = true
+				tripSessionIDs = append(tripSessionIDs, sessionID)
+			}
+		}
+		sort.Slice(tripSessionIDs, func(i, j int) bool {
+			return sessionOrder[tripSessionIDs[i]] < sessionOrder[tripSessionIDs[j]]
+		})
+		if err := insertTripSessions(tx, tripID, tripSessionIDs); err != nil {
+			return err
+		}
+	}
+
+	for _, stmt := range []string{
+		`ALTER TABLE sessions DROP COLUMN asset_ids`,
+		`ALTER TABLE trips DROP COLUMN asset_ids`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to drop asset_ids column: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// markCustomTripNames marks trips renamed before custom names were tracked, which migration 10
+// left marked as generated. A name counts as generated only if it is exactly what the
+// original trip name generator could have produced: the trip's most common city and
+// country, or "Trip", followed by its dates. Anything else is kept as a custom name, so an
+// uncertain match never loses a rename.
+func markCustomTripNames(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, COALESCE(name, ''), start_time, end_time FROM trips WHERE COALESCE(custom_name, 0) = 0`)
+	if err != nil {
+		return err
+	}
+	type storedTrip struct {
+		id         int64
+		name       string
+		start, end time.Time
+	}
+	var trips []storedTrip
+	for rows.Next() {
+		var t storedTrip
+		if err := rows.Scan(&t.id, &t.name, &t.start, &t.end); err != nil {
+			rows.Close()
+			return err
+		}
+		trips = append(trips, t)
+	}
+	rows.Close()
+
+	for _, t := range trips {
+		locations, err := legacyTripLocations(tx, t.id)
+		if err != nil {
+			return err
+		}
+		dates := legacyTripDates(t.start, t.end)
+		generated := false
+		for _, location := range append(locations, "Trip") {
+			if t.name == location+" - "+dates {
+				generated = true
+				break
+			}
+		}
+		if generated {
+			continue
+		}
+		if _, err := tx.Exec(`UPDATE trips SET custom_name = 1 WHERE id = ?`, t.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// legacyTripLocations returns every location the old trip name generator could have picked
+// for a trip: its most common city and country, with all combinations on ties
+func legacyTripLocations(tx *sql.Tx, tripID int64) ([]string, error) {
+	rows, err := tx.Query(`
+		SELECT COALESCE(a.city, ''), COALESCE(a.country, '')
+		FROM trip_assets t JOIN assets a ON a.id = t.asset_id
+		WHERE t.trip_id = ?
+	`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cityCount := make(map[string]int)
+	countryCount := make(map[string]int)
+	for rows.Next() {
+		var city, country string
+		if err := rows.Scan(&city, &country); err != nil {
+			return nil, err
+		}
+		if city != "" {
+			cityCount[city]++
+		}
+		if country != "" {
+			countryCount[country]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	cities, countries := mostCommon(cityCount), mostCommon(countryCount)
+	switch {
+	case len(cities) == 0:
+		return countries, nil
+	case len(countries) == 0:
+		return cities, nil
+	}
+	var locations []string
+	for _, city := range cities {
+		for _, country := range countries {
+			locations = append(locations, city+", "+country)
+		}
+	}
+	return locations, nil
+}
+
+// mostCommon returns the keys with the highest count
+func mostCommon(counts map[string]int) []string {
+	best := 0
+	var keys []string
+	for key, count := range counts {
+		switch {
+		case count > best:
+			best, keys = count, []string{key}
+		case count == best:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// legacyTripDates formats trip dates the way the old trip name generator did
+func legacyTripDates(start, end time.Time) string {
+	switch {
+	case start.Year() == end.Year() && start.Month() == end.Month() && start.Day() == end.Day():
+		return start.Format("Jan 2, 2006")
+	case start.Year() == end.Year() && start.Month() == end.Month():
+		return fmt.Sprintf("%s %d-%d, %d", start.Format("Jan"), start.Day(), end.Day(), start.Year())
+	default:
+		return fmt.Sprintf("%s - %s", start.Format("Jan 2"), end.Format("Jan 2, 2006"))
+	}
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SyncState is the incremental sync high-water mark for one date-range scope
+type SyncState struct {
+	Scope         string
+	LastUpdatedAt time.Time // Latest Immich updatedAt seen in this scope
+	LastSyncedAt  time.Time // When the last sync finished
+}
+
+// GetSyncState returns the sync state for a scope, or nil if it has never been synced
+func (db *DB) GetSyncState(scope string) (*SyncState, error) {
+	state := SyncState{Scope: scope}
+	var lastUpdatedAt, lastSyncedAt sql.NullTime
+
+	err := db.conn.QueryRow(`
+		SELECT last_updated_at, last_synced_at FROM sync_state WHERE scope = ?
+	`, scope).Scan(&lastUpdatedAt, &lastSyncedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state.LastUpdatedAt = lastUpdatedAt.Time
+	state.LastSyncedAt = lastSyncedAt.Time
+	return &state, nil
+}
+
+// StoreSyncState records the sync high-water mark for a scope
+func (db *DB) StoreSyncState(state SyncState) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO sync_state (scope, last_updated_at, last_synced_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(scope) DO UPDATE SET
+			last_updated_at = excluded.last_updated_at,
+			last_synced_at = excluded.last_synced_at
+	`, state.Scope, state.LastUpdatedAt, state.LastSyncedAt)
+	return err
+}
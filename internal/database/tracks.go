@@ -0,0 +1,80 @@
+package database
+
+import (
+	"sort"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// StoreTrackPoints stores track points, ignoring points that already exist for the same
+// photographer and time. Returns the number of points added.
+func (db *DB) StoreTrackPoints(points []models.TrackPoint) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO track_points (photographer, time, latitude, longitude, source)
+		VALUES (?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	added := 0
+	for _, p := range points {
+		result, err := stmt.Exec(p.Photographer, p.Time, p.Latitude, p.Longitude, p.Source)
+		if err != nil {
+			return 0, err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			added++
+		}
+	}
+
+	return added, tx.Commit()
+}
+
+// GetTrackPoints retrieves all track points grouped by photographer and sorted by time
+func (db *DB) GetTrackPoints() (map[string][]models.TrackPoint, error) {
+	rows, err := db.conn.Query(`
+		SELECT photographer, time, latitude, longitude, source
+		FROM track_points
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tracks := make(map[string][]models.TrackPoint)
+	for rows.Next() {
+		var p models.TrackPoint
+		if err := rows.Scan(&p.Photographer, &p.Time, &p.Latitude, &p.Longitude, &p.Source); err != nil {
+			return nil, err
+		}
+		tracks[p.Photographer] = append(tracks[p.Photographer], p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, points := range tracks {
+		sort.Slice(points, func(i, j int) bool {
+			return points[i].Time.Before(points[j].Time)
+		})
+	}
+
+	return tracks, nil
+}
+
+// DeleteTrackPoints removes all track points of a photographer
+func (db *DB) DeleteTrackPoints(photographer string) (int64, error) {
+	result, err := db.conn.Exec("DELETE FROM track_points WHERE photographer = ?", photographer)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// GetTripRules retrieves all trip rules in the order they were created
+func (db *DB) GetTripRules() ([]models.TripRule, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, type, COALESCE(asset_id, ''), COALESCE(target_asset_id, ''), time,
+			COALESCE(description, ''), created_at
+		FROM trip_rules
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []models.TripRule
+	for rows.Next() {
+		var r models.TripRule
+		var ruleTime, createdAt sql.NullTime
+		err := rows.Scan(&r.ID, &r.Type, &r.AssetID, &r.TargetAssetID, &ruleTime, &r.Description, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+		r.Time = ruleTime.Time
+		r.CreatedAt = createdAt.Time
+		rules = append(rules, r)
+	}
+
+	return rules, rows.Err()
+}
+
+// StoreTripRule inserts a trip rule and returns its ID
+func (db *DB) StoreTripRule(r models.TripRule) (int64, error) {
+	result, err := db.conn.Exec(`
+		INSERT INTO trip_rules (type, asset_id, target_asset_id, time, description, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, r.Type, r.AssetID, r.TargetAssetID, nullTime(r.Time), r.Description, nullTime(r.CreatedAt))
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// ReplaceTripRules replaces all trip rules, keeping their order
+func (db *DB) ReplaceTripRules(rules []models.TripRule) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM trip_rules"); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO trip_rules (type, asset_id, target_asset_id, time, description, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, r := range rules {
+		_, err := stmt.Exec(r.Type, r.AssetID, r.TargetAssetID, nullTime(r.Time), r.Description, nullTime(r.CreatedAt))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTripRule removes a trip rule by ID
+func (db *DB) DeleteTripRule(id int64) error {
+	result, err := db.conn.Exec("DELETE FROM trip_rules WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("trip rule %d not found", id)
+	}
+	return nil
+}
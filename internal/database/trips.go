@@ -8,24 +8,27 @@ import (
 	"github.com/jamo/immich-albums/internal/models"
 )
 
-// StoreTrips saves trips to the database
+// StoreTrips replaces all trips. Trips with an ID keep it along with their name, album ID
+// and exclusion; new trips get a fresh ID.
 func (db *DB) StoreTrips(trips []models.Trip) error {
-	// Clear existing trips
-	if _, err := db.conn.Exec("DELETE FROM trips"); err != nil {
-		return err
-	}
-
 	tx, err := db.conn.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	// Clear existing trips
+	for _, table := range []string{"trips", "trip_assets", "trip_sessions"} {
+		if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+			return err
+		}
+	}
+
 	stmt, err := tx.Prepare(`
 		INSERT INTO trips (
-			name, start_time, end_time, home_distance, total_distance,
-			center_lat, center_lon, asset_ids, photographers, session_count
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			id, name, custom_name, start_time, end_time, home_distance, total_distance,
+			center_lat, center_lon, photographers, session_count, album_id, exclude_from_album, summary
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return err
@@ -33,23 +36,36 @@ func (db *DB) StoreTrips(trips []models.Trip) error {
 	defer stmt.Close()
 
 	for _, trip := range trips {
-		assetIDs, _ := json.Marshal(trip.AssetIDs)
-
-		_, err := stmt.Exec(
+		var id interface{}
+		if trip.ID != 0 {
+			id = trip.ID
+		}
+		result, err := stmt.Exec(
+			id,
 			trip.Name,
+			trip.CustomName,
 			trip.StartTime,
 			trip.EndTime,
 			trip.HomeDistance,
 			trip.TotalDistance,
 			trip.CenterLat,
 			trip.CenterLon,
-			string(assetIDs),
 			trip.Photographers,
 			trip.SessionCount,
+			trip.AlbumID,
+			trip.ExcludeFromAlbum,
+			tripSummaryJSON(trip.Summary),
 		)
 		if err != nil {
 			return err
 		}
+		trip.ID, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		if err := replaceTripLinks(tx, &trip); err != nil {
+			return err
+		}
 	}
 
 	return tx.Commit()
@@ -58,9 +74,9 @@ func (db *DB) StoreTrips(trips []models.Trip) error {
 // GetTrips retrieves all trips from the database
 func (db *DB) GetTrips() ([]models.Trip, error) {
 	rows, err := db.conn.Query(`
-		SELECT id, name, start_time, end_time, home_distance, total_distance,
-			center_lat, center_lon, asset_ids, photographers, session_count,
-			COALESCE(album_id, ''), COALESCE(exclude_from_album, 0)
+		SELECT id, name, COALESCE(custom_name, 0), start_time, end_time, home_distance, total_distance,
+			center_lat, center_lon, photographers, session_count,
+			COALESCE(album_id, ''), COALESCE(exclude_from_album, 0), summary
 		FROM trips
 		ORDER BY start_time DESC
 	`)
@@ -72,32 +88,42 @@ func (db *DB) GetTrips() ([]models.Trip, error) {
 	var trips []models.Trip
 	for rows.Next() {
 		var trip models.Trip
-		var assetIDsJSON string
 		var excludeInt int
+		var summary sql.NullString
 
 		err := rows.Scan(
 			&trip.ID,
 			&trip.Name,
+			&trip.CustomName,
 			&trip.StartTime,
 			&trip.EndTime,
 			&trip.HomeDistance,
 			&trip.TotalDistance,
 			&trip.CenterLat,
 			&trip.CenterLon,
-			&assetIDsJSON,
 			&trip.Photographers,
 			&trip.SessionCount,
 			&trip.AlbumID,
 			&excludeInt,
+			&summary,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if trip.Summary, err = scanTripSummary(summary); err != nil {
+			return nil, fmt.Errorf("invalid summary for trip %d: %w", trip.ID, err)
+		}
 
-		json.Unmarshal([]byte(assetIDsJSON), &trip.AssetIDs)
 		trip.ExcludeFromAlbum = excludeInt == 1
 		trips = append(trips, trip)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := db.attachTripLinks(trips); err != nil {
+		return nil, err
+	}
 
 	return trips, nil
 }
@@ -105,29 +131,30 @@ func (db *DB) GetTrips() ([]models.Trip, error) {
 // GetTrip retrieves a single trip by ID
 func (db *DB) GetTrip(id int64) (*models.Trip, error) {
 	var trip models.Trip
-	var assetIDsJSON string
 	var excludeInt int
+	var summary sql.NullString
 
 	err := db.conn.QueryRow(`
-		SELECT id, name, start_time, end_time, home_distance, total_distance,
-			center_lat, center_lon, asset_ids, photographers, session_count,
-			COALESCE(album_id, ''), COALESCE(exclude_from_album, 0)
+		SELECT id, name, COALESCE(custom_name, 0), start_time, end_time, home_distance, total_distance,
+			center_lat, center_lon, photographers, session_count,
+			COALESCE(album_id, ''), COALESCE(exclude_from_album, 0), summary
 		FROM trips
 		WHERE id = ?
 	`, id).Scan(
 		&trip.ID,
 		&trip.Name,
+		&trip.CustomName,
 		&trip.StartTime,
 		&trip.EndTime,
 		&trip.HomeDistance,
 		&trip.TotalDistance,
 		&trip.CenterLat,
 		&trip.CenterLon,
-		&assetIDsJSON,
 		&trip.Photographers,
 		&trip.SessionCount,
 		&trip.AlbumID,
 		&excludeInt,
+		&summary,
 	)
 
 	if err == sql.ErrNoRows {
@@ -136,11 +163,18 @@ func (db *DB) GetTrip(id int64) (*models.Trip, error) {
 	if err != nil {
 		return nil, err
 	}
+	if trip.Summary, err = scanTripSummary(summary); err != nil {
+		return nil, fmt.Errorf("invalid summary for trip %d: %w", trip.ID, err)
+	}
 
-	json.Unmarshal([]byte(assetIDsJSON), &trip.AssetIDs)
 	trip.ExcludeFromAlbum = excludeInt == 1
 
-	return &trip, nil
+	trips := []models.Trip{trip}
+	if err := db.attachTripLinks(trips); err != nil {
+		return nil, err
+	}
+
+	return &trips[0], nil
 }
 
 // UpdateTripAlbumID updates the album_id for a trip
@@ -151,26 +185,78 @@ func (db *DB) UpdateTripAlbumID(tripID int64, albumID string) error {
 	return err
 }
 
-// UpdateTrip updates trip details
+// UpdateTrip updates trip details, including its assets and sessions
 func (db *DB) UpdateTrip(trip *models.Trip) error {
-	assetIDs, _ := json.Marshal(trip.AssetIDs)
 	excludeInt := 0
 	if trip.ExcludeFromAlbum {
 		excludeInt = 1
 	}
 
-	_, err := db.conn.Exec(`
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
 		UPDATE trips
-		SET name = ?, start_time = ?, end_time = ?,
+		SET name = ?, custom_name = ?, start_time = ?, end_time = ?,
 			home_distance = ?, total_distance = ?,
 			center_lat = ?, center_lon = ?,
-			asset_ids = ?, photographers = ?, session_count = ?,
-			album_id = ?, exclude_from_album = ?
+			photographers = ?, session_count = ?,
+			album_id = ?, exclude_from_album = ?, summary = ?
 		WHERE id = ?
-	`, trip.Name, trip.StartTime, trip.EndTime,
+	`, trip.Name, trip.CustomName, trip.StartTime, trip.EndTime,
 		trip.HomeDistance, trip.TotalDistance,
 		trip.CenterLat, trip.CenterLon,
-		string(assetIDs), trip.Photographers, trip.SessionCount,
-		trip.AlbumID, excludeInt, trip.ID)
-	return err
+		trip.Photographers, trip.SessionCount,
+		trip.AlbumID, excludeInt, tripSummaryJSON(trip.Summary), trip.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceTripLinks(tx, trip); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTrip removes a trip by ID
+func (db *DB) DeleteTrip(id int64) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"trip_assets", "trip_sessions"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE trip_id = ?", id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM trips WHERE id = ?", id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// tripSummaryJSON stores a trip summary as JSON
+func tripSummaryJSON(summary models.TripSummary) interface{} {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return nil
+	}
+	return string(data)
+}
+
+// scanTripSummary reads a trip summary, which is empty for trips detected before summaries
+func scanTripSummary(s sql.NullString) (models.TripSummary, error) {
+	var summary models.TripSummary
+	if !s.Valid || s.String == "" {
+		return summary, nil
+	}
+	err := json.Unmarshal([]byte(s.String), &summary)
+	return summary, err
 }
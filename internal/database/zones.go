@@ -0,0 +1,115 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// GetZones retrieves all zones, optionally only those of one kind
+func (db *DB) GetZones(kind string) ([]models.Zone, error) {
+	query := `
+		SELECT id, name, kind, area, COALESCE(latitude, 0), COALESCE(longitude, 0), COALESCE(radius, 0), created_at
+		FROM zones`
+	var args []interface{}
+	if kind != "" {
+		query += " WHERE kind = ?"
+		args = append(args, kind)
+	}
+	rows, err := db.conn.Query(query+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var zones []models.Zone
+	for rows.Next() {
+		var z models.Zone
+		var area sql.NullString
+		var createdAt sql.NullTime
+		if err := rows.Scan(&z.ID, &z.Name, &z.Kind, &area, &z.Latitude, &z.Longitude, &z.Radius, &createdAt); err != nil {
+			return nil, err
+		}
+		if z.Area, err = scanPolygon(area); err != nil {
+			return nil, fmt.Errorf("invalid area for zone %d: %w", z.ID, err)
+		}
+		z.CreatedAt = createdAt.Time
+		zones = append(zones, z)
+	}
+
+	return zones, rows.Err()
+}
+
+// StoreZone inserts a zone and returns its ID
+func (db *DB) StoreZone(z models.Zone) (int64, error) {
+	if len(z.Area) == 0 && z.Radius <= 0 {
+		return 0, fmt.Errorf("zone %q has neither an area nor a radius", z.Name)
+	}
+	result, err := db.conn.Exec(`
+		INSERT INTO zones (name, kind, area, latitude, longitude, radius, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, z.Name, z.Kind, nullPolygon(z.Area), z.Latitude, z.Longitude, z.Radius, nullTime(z.CreatedAt))
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// ReplaceZones replaces all zones
+func (db *DB) ReplaceZones(zones []models.Zone) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM zones"); err != nil {
+		return err
+	}
+	for _, z := range zones {
+		_, err := tx.Exec(`
+			INSERT INTO zones (name, kind, area, latitude, longitude, radius, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, z.Name, z.Kind, nullPolygon(z.Area), z.Latitude, z.Longitude, z.Radius, nullTime(z.CreatedAt))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteZone removes a zone by ID
+func (db *DB) DeleteZone(id int64) error {
+	result, err := db.conn.Exec("DELETE FROM zones WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("zone %d not found", id)
+	}
+	return nil
+}
+
+// nullPolygon stores a polygon as GeoJSON coordinates, and an empty polygon as NULL
+func nullPolygon(p models.Polygon) interface{} {
+	if len(p) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil
+	}
+	return string(data)
+}
+
+func scanPolygon(s sql.NullString) (models.Polygon, error) {
+	if !s.Valid || s.String == "" {
+		return nil, nil
+	}
+	var p models.Polygon
+	if err := json.Unmarshal([]byte(s.String), &p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
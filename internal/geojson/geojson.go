@@ -0,0 +1,171 @@
+// Package geojson reads polygons for home locations and zones from GeoJSON files and writes
+// exported tracks.
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// Shape is a named polygon read from a GeoJSON file
+type Shape struct {
+	Name string
+	Area models.Polygon
+}
+
+type object struct {
+	Type        string          `json:"type"`
+	Features    []object        `json:"features"`    // FeatureCollection
+	Geometry    *object         `json:"geometry"`    // Feature
+	Properties  map[string]any  `json:"properties"`  // Feature
+	Geometries  []object        `json:"geometries"`  // GeometryCollection
+	Coordinates json.RawMessage `json:"coordinates"` // Polygon, MultiPolygon
+}
+
+// ParseFile reads the polygons of a GeoJSON file
+func ParseFile(path string) ([]Shape, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse reads the polygons of a GeoJSON document, which may be a feature collection, a
+// feature or a bare geometry. Multipolygons become one shape per polygon. Features are named
+// by their "name" or "title" property. Other geometry types are skipped.
+func Parse(data []byte) ([]Shape, error) {
+	var root object
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+
+	var shapes []Shape
+	if err := collect(root, "", &shapes); err != nil {
+		return nil, err
+	}
+	if len(shapes) == 0 {
+		return nil, fmt.Errorf("no polygons found")
+	}
+	return shapes, nil
+}
+
+func collect(obj object, name string, shapes *[]Shape) error {
+	switch obj.Type {
+	case "FeatureCollection":
+		for _, feature := range obj.Features {
+			if err := collect(feature, "", shapes); err != nil {
+				return err
+			}
+		}
+	case "Feature":
+		if obj.Geometry != nil {
+			return collect(*obj.Geometry, featureName(obj.Properties), shapes)
+		}
+	case "GeometryCollection":
+		for _, geometry := range obj.Geometries {
+			if err := collect(geometry, name, shapes); err != nil {
+				return err
+			}
+		}
+	case "Polygon":
+		var area models.Polygon
+		if err := json.Unmarshal(obj.Coordinates, &area); err != nil {
+			return fmt.Errorf("invalid polygon coordinates: %w", err)
+		}
+		return addShape(shapes, name, area)
+	case "MultiPolygon":
+		var areas []models.Polygon
+		if err := json.Unmarshal(obj.Coordinates, &areas); err != nil {
+			return fmt.Errorf("invalid multipolygon coordinates: %w", err)
+		}
+		for _, area := range areas {
+			if err := addShape(shapes, name, area); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func addShape(shapes *[]Shape, name string, area models.Polygon) error {
+	if err := Validate(area); err != nil {
+		return err
+	}
+	if name == "" {
+		name = fmt.Sprintf("Area %d", len(*shapes)+1)
+	}
+	*shapes = append(*shapes, Shape{Name: name, Area: area})
+	return nil
+}
+
+// Validate checks that a polygon has an outline of at least three positions with valid
+// coordinates
+func Validate(area models.Polygon) error {
+	if len(area) == 0 || len(area[0]) < 3 {
+		return fmt.Errorf("polygon needs an outline of at least 3 positions")
+	}
+	for _, ring := range area {
+		for _, pos := range ring {
+			if pos[0] < -180 || pos[0] > 180 || pos[1] < -90 || pos[1] > 90 {
+				return fmt.Errorf("position %v is not a valid [longitude, latitude]", pos)
+			}
+		}
+	}
+	return nil
+}
+
+func featureName(properties map[string]any) string {
+	for _, key := range []string{"name", "Name", "title"} {
+		if s, ok := properties[key].(string); ok && strings.TrimSpace(s) != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
+
+// WriteTrack writes points as a GeoJSON feature collection with the route as a line string
+// and every point as a feature with its time and photographer
+func WriteTrack(w io.Writer, name string, points []models.TrackPoint) error {
+	type geometry struct {
+		Type        string `json:"type"`
+		Coordinates any    `json:"coordinates"`
+	}
+	type feature struct {
+		Type       string         `json:"type"`
+		Geometry   geometry       `json:"geometry"`
+		Properties map[string]any `json:"properties"`
+	}
+
+	features := []feature{}
+	line := make([][2]float64, 0, len(points))
+	for _, p := range points {
+		line = append(line, [2]float64{p.Longitude, p.Latitude})
+	}
+	if len(line) > 1 {
+		features = append(features, feature{
+			Type:       "Feature",
+			Geometry:   geometry{Type: "LineString", Coordinates: line},
+			Properties: map[string]any{"name": name},
+		})
+	}
+	for i, p := range points {
+		features = append(features, feature{
+			Type:     "Feature",
+			Geometry: geometry{Type: "Point", Coordinates: line[i]},
+			Properties: map[string]any{
+				"time":         p.Time.Format("2006-01-02T15:04:05"),
+				"photographer": p.Photographer,
+			},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]any{"type": "FeatureCollection", "name": name, "features": features})
+}
@@ -0,0 +1,217 @@
+// Package i18n provides the message catalogs used for trip names and album descriptions.
+//
+// English and Finnish are built in. More languages can be added without recompiling by
+// putting a catalog named after the locale, e.g. sv.json, in the locale directory. Keys a
+// catalog leaves out fall back to English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLocale is used when no locale is set
+const DefaultLocale = "en"
+
+//go:embed catalogs/*.json
+var builtinCatalogs embed.FS
+
+// Catalog holds the translated messages, month names and date formats of one locale
+type Catalog struct {
+	Locale      string            `json:"locale"`
+	Name        string            `json:"name"`         // Name of the language in itself
+	Months      []string          `json:"months"`       // January to December
+	ShortMonths []string          `json:"short_months"` // Jan to Dec
+	Formats     map[string]string `json:"formats"`      // Date formats, see FormatDate
+	Messages    map[string]string `json:"messages"`
+
+	DecimalSeparator string `json:"decimal_separator,omitempty"` // "." when empty
+}
+
+// English returns the built-in English catalog
+func English() *Catalog {
+	c, err := readCatalog(builtinCatalogs, "catalogs/en.json")
+	if err != nil {
+		panic(fmt.Sprintf("built-in English catalog: %v", err))
+	}
+	return c
+}
+
+// Load returns the catalog of a locale. A catalog file in dir takes precedence over the
+// built-in one, and anything missing falls back to English. dir may be empty.
+func Load(locale, dir string) (*Catalog, error) {
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	catalog := English()
+
+	found := locale == DefaultLocale
+	if c, err := readCatalog(builtinCatalogs, "catalogs/"+locale+".json"); err == nil {
+		catalog.merge(c)
+		found = true
+	}
+	if dir != "" {
+		c, err := readCatalog(os.DirFS(dir), locale+".json")
+		switch {
+		case err == nil:
+			catalog.merge(c)
+			found = true
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("failed to load catalog %s: %w", filepath.Join(dir, locale+".json"), err)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("unknown locale %q (available: %s)", locale, strings.Join(Available(dir), ", "))
+	}
+
+	catalog.Locale = locale
+	return catalog, nil
+}
+
+// Available lists the built-in locales and those with a catalog in dir
+func Available(dir string) []string {
+	matches, _ := fs.Glob(builtinCatalogs, "catalogs/*.json")
+	if dir != "" {
+		local, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+		matches = append(matches, local...)
+	}
+
+	seen := make(map[string]bool)
+	var locales []string
+	for _, match := range matches {
+		locale := strings.TrimSuffix(filepath.Base(match), ".json")
+		if !seen[locale] {
+			seen[locale] = true
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+func readCatalog(fsys fs.FS, name string) (*Catalog, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if (len(c.Months) != 0 && len(c.Months) != 12) || (len(c.ShortMonths) != 0 && len(c.ShortMonths) != 12) {
+		return nil, fmt.Errorf("months and short_months need 12 names each")
+	}
+	return &c, nil
+}
+
+// merge overrides the catalog with everything other sets
+func (c *Catalog) merge(other *Catalog) {
+	if other.Name != "" {
+		c.Name = other.Name
+	}
+	if other.DecimalSeparator != "" {
+		c.DecimalSeparator = other.DecimalSeparator
+	}
+	if len(other.Months) == 12 {
+		c.Months = other.Months
+	}
+	if len(other.ShortMonths) == 12 {
+		c.ShortMonths = other.ShortMonths
+	}
+	for key, format := range other.Formats {
+		c.Formats[key] = format
+	}
+	for key, message := range other.Messages {
+		c.Messages[key] = message
+	}
+}
+
+// T returns the message for key with {name} placeholders filled in from name-value pairs,
+// e.g. T("album_photos", "count", 12, "photographers", "Anna"). Unknown keys are returned
+// as they are.
+func (c *Catalog) T(key string, args ...interface{}) string {
+	message, ok := c.Messages[key]
+	if !ok {
+		message = key
+	}
+	return fill(message, args...)
+}
+
+// FormatDate formats dates with one of the catalog's date formats. Formats use {d} and {dd}
+// for the day, {m} and {mm} for the month number, {mon} and {month} for the short and long
+// month name and {yyyy} for the year. The same placeholders prefixed with end_ refer to end,
+// for formats of date ranges.
+func (c *Catalog) FormatDate(format string, start, end time.Time) string {
+	layout, ok := c.Formats[format]
+	if !ok {
+		layout = format
+	}
+	var args []interface{}
+	for _, d := range []struct {
+		prefix string
+		t      time.Time
+	}{{"", start}, {"end_", end}} {
+		month := int(d.t.Month())
+		args = append(args,
+			d.prefix+"d", d.t.Day(),
+			d.prefix+"dd", fmt.Sprintf("%02d", d.t.Day()),
+			d.prefix+"m", month,
+			d.prefix+"mm", fmt.Sprintf("%02d", month),
+			d.prefix+"mon", c.ShortMonths[month-1],
+			d.prefix+"month", c.Months[month-1],
+			d.prefix+"yyyy", d.t.Year(),
+		)
+	}
+	return fill(layout, args...)
+}
+
+// DateRange formats the dates of a trip, e.g. "Jan 2, 2006", "Jan 2-5, 2006" or
+// "Jan 30 - Feb 2, 2006" in English
+func (c *Catalog) DateRange(start, end time.Time) string {
+	switch {
+	case start.Year() == end.Year() && start.Month() == end.Month() && start.Day() == end.Day():
+		return c.FormatDate("date", start, start)
+	case start.Year() == end.Year() && start.Month() == end.Month():
+		return c.FormatDate("range_same_month", start, end)
+	default:
+		return c.FormatDate("range", start, end)
+	}
+}
+
+// List joins items as "A, B and C"
+func (c *Catalog) List(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " " + c.T("and") + " " + items[len(items)-1]
+}
+
+// Number formats a number with the given number of decimals
+func (c *Catalog) Number(f float64, decimals int) string {
+	s := strconv.FormatFloat(f, 'f', decimals, 64)
+	if c.DecimalSeparator != "" {
+		s = strings.Replace(s, ".", c.DecimalSeparator, 1)
+	}
+	return s
+}
+
+// fill replaces {name} placeholders with the values of name-value pairs
+func fill(message string, args ...interface{}) string {
+	if len(args) == 0 || !strings.Contains(message, "{") {
+		return message
+	}
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+fmt.Sprint(args[i])+"}", fmt.Sprint(args[i+1]))
+	}
+	return strings.NewReplacer(pairs...).Replace(message)
+}
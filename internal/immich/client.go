@@ -3,6 +3,7 @@ package immich
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +12,9 @@ import (
 	"github.com/jamo/immich-albums/internal/models"
 )
 
+// ErrNotFound is returned when a requested Immich resource does not exist
+var ErrNotFound = errors.New("not found in Immich")
+
 type Client struct {
 	baseURL string
 	apiKey  string
@@ -27,6 +31,33 @@ func NewClient(baseURL, apiKey string) *Client {
 
 // FetchAssets retrieves all assets within a date range
 func (c *Client) FetchAssets(start, end time.Time) ([]models.Asset, error) {
+	return c.searchAssets(map[string]interface{}{
+		"takenAfter":  start.Format(time.RFC3339),
+		"takenBefore": end.Format(time.RFC3339),
+	})
+}
+
+// FetchAssetsUpdatedAfter retrieves assets created or modified in Immich after the given time.
+// If start and end are non-zero, results are further limited to assets taken within that range.
+// Trashed assets are included (flagged with IsTrashed) so they can be reconciled locally.
+func (c *Client) FetchAssetsUpdatedAfter(updatedAfter, start, end time.Time) ([]models.Asset, error) {
+	filters := map[string]interface{}{
+		"withDeleted": true,
+	}
+	if !updatedAfter.IsZero() {
+		filters["updatedAfter"] = updatedAfter.Format(time.RFC3339Nano)
+	}
+	if !start.IsZero() {
+		filters["takenAfter"] = start.Format(time.RFC3339)
+	}
+	if !end.IsZero() {
+		filters["takenBefore"] = end.Format(time.RFC3339)
+	}
+	return c.searchAssets(filters)
+}
+
+// searchAssets pages through /api/search/metadata with the given filters
+func (c *Client) searchAssets(filters map[string]interface{}) ([]models.Asset, error) {
 	endpoint := fmt.Sprintf("%s/api/search/metadata", c.baseURL)
 
 	var allAssets []models.Asset
@@ -36,11 +67,12 @@ func (c *Client) FetchAssets(start, end time.Time) ([]models.Asset, error) {
 	for {
 		// Build request body
 		requestBody := map[string]interface{}{
-			"takenAfter":  start.Format(time.RFC3339),
-			"takenBefore": end.Format(time.RFC3339),
-			"page":        page,
-			"size":        size,
-			"withExif":    true,
+			"page":     page,
+			"size":     size,
+			"withExif": true,
+		}
+		for key, value := range filters {
+			requestBody[key] = value
 		}
 
 		jsonBody, err := json.Marshal(requestBody)
@@ -112,7 +144,11 @@ type assetResponse struct {
 	FileCreatedAt    time.Time `json:"fileCreatedAt"`
 	FileModifiedAt   time.Time `json:"fileModifiedAt"`
 	LocalDateTime    time.Time `json:"localDateTime"`
+	UpdatedAt        time.Time `json:"updatedAt"`
 	Duration         string    `json:"duration"`
+	IsTrashed        bool      `json:"isTrashed"`
+	IsArchived       bool      `json:"isArchived"`
+	Visibility       string    `json:"visibility"`
 	ExifInfo         *struct {
 		Make            string   `json:"make"`
 		Model           string   `json:"model"`
@@ -144,7 +180,10 @@ func parseAsset(resp assetResponse) models.Asset {
 		FileCreatedAt:    resp.FileCreatedAt,
 		FileModifiedAt:   resp.FileModifiedAt,
 		LocalDateTime:    resp.LocalDateTime,
+		UpdatedAt:        resp.UpdatedAt,
 		Duration:         resp.Duration,
+		IsTrashed:        resp.IsTrashed,
+		IsArchived:       resp.IsArchived || resp.Visibility == "archive",
 	}
 
 	if resp.ExifInfo != nil {
@@ -274,3 +313,209 @@ func (c *Client) DeleteAlbum(albumID string) error {
 
 	return nil
 }
+
+// Album is an Immich album with the IDs of the assets it contains
+type Album struct {
+	ID          string
+	Name        string
+	Description string
+	AssetIDs    []string
+}
+
+// GetAlbum retrieves an album and its asset IDs
+func (c *Client) GetAlbum(albumID string) (*Album, error) {
+	endpoint := fmt.Sprintf("%s/api/albums/%s", c.baseURL, albumID)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+		return nil, fmt.Errorf("album %s: %w", albumID, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get album with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		ID          string `json:"id"`
+		AlbumName   string `json:"albumName"`
+		Description string `json:"description"`
+		Assets      []struct {
+			ID string `json:"id"`
+		} `json:"assets"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	album := &Album{
+		ID:          response.ID,
+		Name:        response.AlbumName,
+		Description: response.Description,
+		AssetIDs:    make([]string, 0, len(response.Assets)),
+	}
+	for _, asset := range response.Assets {
+		album.AssetIDs = append(album.AssetIDs, asset.ID)
+	}
+
+	return album, nil
+}
+
+// UpdateAlbum changes an album's name and description
+func (c *Client) UpdateAlbum(albumID, name, description string) error {
+	endpoint := fmt.Sprintf("%s/api/albums/%s", c.baseURL, albumID)
+
+	requestBody := map[string]interface{}{
+		"albumName":   name,
+		"description": description,
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PATCH", endpoint, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update album with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// RemoveAssetsFromAlbum removes assets from an album (the assets themselves are kept)
+func (c *Client) RemoveAssetsFromAlbum(albumID string, assetIDs []string) error {
+	endpoint := fmt.Sprintf("%s/api/albums/%s/assets", c.baseURL, albumID)
+
+	requestBody := map[string]interface{}{
+		"ids": assetIDs,
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", endpoint, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to remove assets from album with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// GetAsset retrieves a single asset with its current EXIF data
+func (c *Client) GetAsset(assetID string) (models.Asset, error) {
+	endpoint := fmt.Sprintf("%s/api/assets/%s", c.baseURL, assetID)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return models.Asset{}, err
+	}
+
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return models.Asset{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+		return models.Asset{}, fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return models.Asset{}, fmt.Errorf("failed to get asset with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response assetResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return models.Asset{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return parseAsset(response), nil
+}
+
+// UpdateAssetsLocation sets the same latitude and longitude on several assets.
+// Nil coordinates are sent as null, which Immich treats as no change, not as clearing.
+func (c *Client) UpdateAssetsLocation(assetIDs []string, latitude, longitude *float64) error {
+	endpoint := fmt.Sprintf("%s/api/assets", c.baseURL)
+
+	requestBody := map[string]interface{}{
+		"ids":       assetIDs,
+		"latitude":  latitude,
+		"longitude": longitude,
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", endpoint, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update asset locations with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -8,13 +8,17 @@ type Asset struct {
 	DeviceAssetID    string    `json:"device_asset_id"`
 	OwnerID          string    `json:"owner_id"`
 	DeviceID         string    `json:"device_id"`
+	DeviceKey        string    `json:"device_key"` // Resolved local device ID (see Device.ID)
 	Type             string    `json:"type"`
 	OriginalPath     string    `json:"original_path"`
 	OriginalFileName string    `json:"original_file_name"`
 	FileCreatedAt    time.Time `json:"file_created_at"`
 	FileModifiedAt   time.Time `json:"file_modified_at"`
 	LocalDateTime    time.Time `json:"local_date_time"`
+	UpdatedAt        time.Time `json:"updated_at"` // Last modification in Immich
 	Duration         string    `json:"duration"`
+	IsTrashed        bool      `json:"is_trashed"`  // Only set on freshly fetched assets
+	IsArchived       bool      `json:"is_archived"` // Only set on freshly fetched assets
 
 	// EXIF data
 	Make            string   `json:"make"`
@@ -36,11 +40,47 @@ type Asset struct {
 
 // Device represents a camera or phone
 type Device struct {
-	ID           string `json:"id"`
-	Make         string `json:"make"`
-	Model        string `json:"model"`
-	PhotoCount   int    `json:"photo_count"`
-	Photographer string `json:"photographer"`
+	ID           string        `json:"id"`
+	Make         string        `json:"make"`
+	Model        string        `json:"model"`
+	PhotoCount   int           `json:"photo_count"`
+	Photographer string        `json:"photographer"`
+	CounterRange *CounterRange `json:"counter_range,omitempty"` // Set for devices identified by filename counters
+}
+
+// CounterRange is the span of filename counters (IMG_1234 -> 1234) seen from one physical device
+type CounterRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// Clock offset statuses
+const (
+	ClockOffsetSuggested = "suggested" // Estimated, not applied until accepted
+	ClockOffsetAccepted  = "accepted"  // Estimated and accepted by the user
+	ClockOffsetManual    = "manual"    // Entered by the user, overrides estimates
+)
+
+// ClockOffset corrects a device's clock over a date range. The offset is added to the
+// device's timestamps; the range is in the device's own (uncorrected) time.
+type ClockOffset struct {
+	ID            int64     `json:"id"`
+	DeviceID      string    `json:"device_id"`
+	ValidFrom     time.Time `json:"valid_from"`     // Zero means no lower bound
+	ValidTo       time.Time `json:"valid_to"`       // Zero means no upper bound
+	OffsetSeconds int64     `json:"offset_seconds"` // Seconds to add to the device's timestamps
+	Confidence    float64   `json:"confidence"`     // 0.0 to 1.0, 1.0 for manual offsets
+	Matches       int       `json:"matches"`        // Photos aligned with phone photos at this offset
+	Status        string    `json:"status"`
+}
+
+// TrackPoint is a GPS fix from an imported track (GPX, KML or Google Timeline)
+type TrackPoint struct {
+	Photographer string    `json:"photographer"`
+	Time         time.Time `json:"time"` // Local wall-clock time, comparable with Asset.LocalDateTime
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	Source       string    `json:"source"` // File the point was imported from
 }
 
 // Location represents a geographic point with confidence
@@ -52,22 +92,40 @@ type Location struct {
 	Timestamp  time.Time
 }
 
+// LocationInference contains inferred location data
+type LocationInference struct {
+	AssetID    string
+	Latitude   float64
+	Longitude  float64
+	Confidence float64
+	Source     string // "track", "nearby", "interpolated", "same-session"
+	Method     string // Description of how it was inferred
+}
+
 // Session represents a group of photos taken in proximity (time and space)
 type Session struct {
-	ID           int64     `json:"id"`
-	StartTime    time.Time `json:"start_time"`
-	EndTime      time.Time `json:"end_time"`
-	AssetIDs     []string  `json:"asset_ids"`
-	CenterLat    float64   `json:"center_lat"`
-	CenterLon    float64   `json:"center_lon"`
-	Radius       float64   `json:"radius"` // meters
-	Photographer string    `json:"photographer"`
+	ID           int64               `json:"id"`
+	StartTime    time.Time           `json:"start_time"`
+	EndTime      time.Time           `json:"end_time"`
+	AssetIDs     []string            `json:"asset_ids"`
+	CenterLat    float64             `json:"center_lat"`
+	CenterLon    float64             `json:"center_lon"`
+	Radius       float64             `json:"radius"` // meters
+	Photographer string              `json:"photographer"`
+	Attachments  []SessionAttachment `json:"attachments,omitempty"` // Assets added by time only, also listed in AssetIDs
+}
+
+// SessionAttachment records why an asset without a usable location was added to a session
+type SessionAttachment struct {
+	AssetID string `json:"asset_id"`
+	Reason  string `json:"reason"`
 }
 
 // Trip represents a collection of sessions that form a journey
 type Trip struct {
 	ID               int64     `json:"id"`
 	Name             string    `json:"name"`
+	CustomName       bool      `json:"custom_name"` // Name was set by the user and survives re-detection
 	StartTime        time.Time `json:"start_time"`
 	EndTime          time.Time `json:"end_time"`
 	Sessions         []Session `json:"sessions"`
@@ -80,13 +138,95 @@ type Trip struct {
 	SessionCount     int       `json:"session_count"`
 	AlbumID          string    `json:"album_id"`            // Immich album ID
 	ExcludeFromAlbum bool      `json:"exclude_from_album"` // If true, don't create album for this trip
+
+	Summary TripSummary `json:"summary"` // Route and statistics computed from the photos
+}
+
+// TripSummary describes where a trip went, computed from the locations of its photos
+type TripSummary struct {
+	Countries        []string    `json:"countries,omitempty"` // In the order they were first visited
+	Cities           []string    `json:"cities,omitempty"`    // In the order they were first visited
+	Days             []TripDay   `json:"days,omitempty"`      // Every calendar day of the trip
+	Flights          []FlightLeg `json:"flights,omitempty"`
+	FarthestDistance float64     `json:"farthest_distance"` // km from the nearest home, 0 without homes
+	NightsAway       int         `json:"nights_away"`
+}
+
+// TripDay is where a trip was on one day
+type TripDay struct {
+	Date      string  `json:"date"` // YYYY-MM-DD in local photo time
+	City      string  `json:"city,omitempty"`
+	Country   string  `json:"country,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty"` // Mean location of the day's photos in City
+	Longitude float64 `json:"longitude,omitempty"`
+	Photos    int     `json:"photos"`
+}
+
+// FlightLeg is a jump between two photos too fast to have been made on the ground
+type FlightLeg struct {
+	From       string    `json:"from,omitempty"` // City of the last photo before the flight
+	To         string    `json:"to,omitempty"`   // City of the first photo after the flight
+	Departure  time.Time `json:"departure"`      // Time of the last photo before the flight
+	Arrival    time.Time `json:"arrival"`        // Time of the first photo after the flight
+	FromLat    float64   `json:"from_lat"`
+	FromLon    float64   `json:"from_lon"`
+	ToLat      float64   `json:"to_lat"`
+	ToLon      float64   `json:"to_lon"`
+	DistanceKM float64   `json:"distance_km"`
+}
+
+// Trip rule types
+const (
+	TripRuleMerge  = "merge"  // Merge the trips containing AssetID and TargetAssetID
+	TripRuleSplit  = "split"  // Start a new trip at the session containing AssetID, or at Time
+	TripRuleAttach = "attach" // Move the session containing AssetID into the trip containing TargetAssetID
+	TripRuleDetach = "detach" // Remove the session containing AssetID from its trip
+)
+
+// TripRule is a manual adjustment applied on every trip detection. Sessions and trips are
+// identified by one of their assets, so rules survive re-running detect-sessions.
+type TripRule struct {
+	ID            int64     `json:"id"`
+	Type          string    `json:"type"`
+	AssetID       string    `json:"asset_id,omitempty"`
+	TargetAssetID string    `json:"target_asset_id,omitempty"`
+	Time          time.Time `json:"time"` // Split time, zero when splitting at a session
+	Description   string    `json:"description"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // HomeLocation represents a user-defined home base
 type HomeLocation struct {
-	ID        int64   `json:"id"`
-	Name      string  `json:"name"`
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
-	Radius    float64 `json:"radius"` // meters
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	Radius       float64   `json:"radius"`         // km, used when there is no area
+	Area         Polygon   `json:"area,omitempty"` // Outline of the home, replaces the radius when set
+	ValidFrom    time.Time `json:"valid_from"`     // Moved in, zero means no lower bound
+	ValidTo      time.Time `json:"valid_to"`       // Moved out, zero means no upper bound
+	Photographer string    `json:"photographer"`   // Whose home this is, empty for everyone
+}
+
+// Polygon holds GeoJSON polygon coordinates: rings of [longitude, latitude] positions, the
+// first ring being the outline and any further rings holes
+type Polygon [][][2]float64
+
+// Zone kinds
+const (
+	ZoneExclude = "exclude" // Sessions inside never count as away from home
+	ZonePrivacy = "privacy" // Photos inside are left out of albums and exports
+)
+
+// Zone is a named area drawn on the map or imported from GeoJSON, either a polygon or a
+// point with a radius
+type Zone struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Area      Polygon   `json:"area,omitempty"`
+	Latitude  float64   `json:"latitude,omitempty"`
+	Longitude float64   `json:"longitude,omitempty"`
+	Radius    float64   `json:"radius,omitempty"` // km, used when there is no area
+	CreatedAt time.Time `json:"created_at"`
 }
@@ -0,0 +1,168 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// Whose sessions an asset without a location may be attached to
+const (
+	AttachSamePhotographer = "same" // Only sessions of the asset's own photographer
+	AttachAnyPhotographer  = "any"  // Any session, preferring the asset's own photographer
+)
+
+// AttachParams controls attaching assets without a usable location to sessions
+type AttachParams struct {
+	Margin        time.Duration // How far before or after a session an asset may be taken
+	Photographers string        // AttachSamePhotographer or AttachAnyPhotographer
+	MinConfidence float64       // Locations below this confidence count as missing
+	MaxDistanceKM float64       // Assets that do have a location must be this close to the session
+}
+
+// AttachUnlocatedAssets adds assets that are in no session because they have no usable
+// location, or no photographer, to the session they were taken during. The assets are
+// appended to the session's AssetIDs and listed in its Attachments with the reason. It
+// returns the number of assets attached.
+func AttachUnlocatedAssets(sessions []models.Session, assets []models.Asset, inferences map[string]models.LocationInference, devices map[string]models.Device, params AttachParams) int {
+	if len(sessions) == 0 {
+		return 0
+	}
+
+	inSession := make(map[string]bool)
+	for _, session := range sessions {
+		for _, assetID := range session.AssetIDs {
+			inSession[assetID] = true
+		}
+	}
+
+	// Sessions by start time, with the longest span to bound the search
+	order := make([]int, len(sessions))
+	var longest time.Duration
+	for i, session := range sessions {
+		order[i] = i
+		if d := session.EndTime.Sub(session.StartTime); d > longest {
+			longest = d
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return sessions[order[i]].StartTime.Before(sessions[order[j]].StartTime)
+	})
+
+	attached := 0
+	for _, asset := range assets {
+		if inSession[asset.ID] {
+			continue
+		}
+
+		photographer := ""
+		if deviceID := ResolveDevice(asset, devices); deviceID != "" {
+			photographer = devices[deviceID].Photographer
+		}
+		lat, lon, hasLoc, conf := GetEffectiveLocation(asset, inferences)
+		located := hasLoc && conf >= params.MinConfidence
+		if located && photographer != "" {
+			continue // Clustering already had everything it needed for this asset
+		}
+		if photographer == "" && params.Photographers == AttachSamePhotographer {
+			continue
+		}
+
+		// Find the best session: own photographer first, then the closest in time
+		t := asset.LocalDateTime
+		best, bestOwn, bestGap := -1, false, time.Duration(0)
+		last := sort.Search(len(order), func(k int) bool {
+			return sessions[order[k]].StartTime.After(t.Add(params.Margin))
+		})
+		for k := last - 1; k >= 0; k-- {
+			session := sessions[order[k]]
+			if session.StartTime.Before(t.Add(-params.Margin - longest)) {
+				break
+			}
+			gap := timeOutside(t, session.StartTime, session.EndTime)
+			if gap > params.Margin {
+				continue
+			}
+			own := photographer != "" && hasPhotographer(session, photographer)
+			if !own && params.Photographers == AttachSamePhotographer {
+				continue
+			}
+			if located && CalculateDistance(lat, lon, session.CenterLat, session.CenterLon) > session.Radius+params.MaxDistanceKM {
+				continue
+			}
+			if best < 0 || (own && !bestOwn) || (own == bestOwn && gap < bestGap) {
+				best, bestOwn, bestGap = order[k], own, gap
+			}
+		}
+		if best < 0 {
+			continue
+		}
+
+		session := &sessions[best]
+		session.AssetIDs = append(session.AssetIDs, asset.ID)
+		session.Attachments = append(session.Attachments, models.SessionAttachment{
+			AssetID: asset.ID,
+			Reason:  attachReason(asset, hasLoc, conf, located, photographer, *session, bestOwn, params.Margin),
+		})
+		inSession[asset.ID] = true
+		attached++
+	}
+
+	return attached
+}
+
+// timeOutside returns how far t is before start or after end, 0 when it is in between
+func timeOutside(t, start, end time.Time) time.Duration {
+	switch {
+	case t.Before(start):
+		return start.Sub(t)
+	case t.After(end):
+		return t.Sub(end)
+	default:
+		return 0
+	}
+}
+
+func hasPhotographer(session models.Session, photographer string) bool {
+	for _, p := range SessionPhotographers(session) {
+		if p == photographer {
+			return true
+		}
+	}
+	return false
+}
+
+// attachReason explains why an asset was attached to a session
+func attachReason(asset models.Asset, hasLoc bool, conf float64, located bool, photographer string, session models.Session, own bool, margin time.Duration) string {
+	var why string
+	switch {
+	case located:
+		why = "unlabeled device"
+	case hasLoc:
+		why = fmt.Sprintf("location confidence %.2f too low", conf)
+	default:
+		why = "no location"
+	}
+	if !located && photographer == "" {
+		why += ", unlabeled device"
+	}
+
+	when := "taken during the session"
+	if asset.LocalDateTime.Before(session.StartTime) {
+		when = fmt.Sprintf("taken %s before the session (margin %s)", session.StartTime.Sub(asset.LocalDateTime).Round(time.Minute), margin)
+	} else if asset.LocalDateTime.After(session.EndTime) {
+		when = fmt.Sprintf("taken %s after the session (margin %s)", asset.LocalDateTime.Sub(session.EndTime).Round(time.Minute), margin)
+	}
+
+	who := fmt.Sprintf("session by %s", session.Photographer)
+	switch {
+	case own:
+		who = fmt.Sprintf("same photographer (%s)", photographer)
+	case photographer != "":
+		who += fmt.Sprintf(", photo by %s", photographer)
+	}
+
+	return fmt.Sprintf("%s; %s; %s", why, when, who)
+}
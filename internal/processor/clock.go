@@ -0,0 +1,302 @@
+package processor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// ClockOffsetParams controls clock offset estimation
+type ClockOffsetParams struct {
+	MaxOffset  time.Duration // Largest offset searched in either direction
+	Tolerance  time.Duration // A camera photo matches a phone photo within this window
+	SegmentGap time.Duration // Gaps longer than this start a new offset range
+	MinMatches int           // Minimum aligned photos to suggest an offset
+}
+
+// DefaultClockOffsetParams returns sensible defaults
+func DefaultClockOffsetParams() ClockOffsetParams {
+	return ClockOffsetParams{
+		MaxOffset:  14 * time.Hour,
+		Tolerance:  10 * time.Minute,
+		SegmentGap: 3 * 24 * time.Hour,
+		MinMatches: 5,
+	}
+}
+
+// Offset search steps: coarse across the whole range, then fine around the best coarse offset
+const (
+	clockCoarseStep = 15 * time.Minute
+	clockFineStep   = time.Minute
+	clockMinOffset  = time.Minute // Smaller offsets are treated as a correct clock
+)
+
+// EstimateClockOffsets suggests clock offsets for labeled devices that mostly lack GPS
+// by aligning their photos with GPS-tagged photos the same photographer took on other
+// devices. Each device's photos are split into ranges at long gaps so that offsets
+// that change over time (time zone trips, daylight saving, clock resets) are found separately.
+func EstimateClockOffsets(assets []models.Asset, devices []models.Device, params ClockOffsetParams) []models.ClockOffset {
+	deviceMap := make(map[string]models.Device)
+	for _, device := range devices {
+		deviceMap[device.ID] = device
+	}
+
+	// Group photo times by device, and GPS photo times by photographer and device
+	deviceTimes := make(map[string][]time.Time)
+	deviceGPS := make(map[string]int)
+	photographerRefs := make(map[string]map[string][]time.Time)
+	for _, asset := range assets {
+		deviceID := ResolveDevice(asset, deviceMap)
+		device, ok := deviceMap[deviceID]
+		if !ok || device.Photographer == "" {
+			continue
+		}
+		deviceTimes[deviceID] = append(deviceTimes[deviceID], asset.LocalDateTime)
+		if asset.Latitude != nil && asset.Longitude != nil {
+			deviceGPS[deviceID]++
+			if photographerRefs[device.Photographer] == nil {
+				photographerRefs[device.Photographer] = make(map[string][]time.Time)
+			}
+			photographerRefs[device.Photographer][deviceID] = append(photographerRefs[device.Photographer][deviceID], asset.LocalDateTime)
+		}
+	}
+
+	deviceIDs := make([]string, 0, len(deviceTimes))
+	for deviceID := range deviceTimes {
+		deviceIDs = append(deviceIDs, deviceID)
+	}
+	sort.Strings(deviceIDs)
+
+	var offsets []models.ClockOffset
+	for _, deviceID := range deviceIDs {
+		times := deviceTimes[deviceID]
+		// Devices that geotag most of their photos are the references, not the ones corrected
+		if float64(deviceGPS[deviceID]) >= float64(len(times))/2 {
+			continue
+		}
+
+		device := deviceMap[deviceID]
+		var refs []time.Time
+		for refDevice, refTimes := range photographerRefs[device.Photographer] {
+			if refDevice != deviceID {
+				refs = append(refs, refTimes...)
+			}
+		}
+		if len(refs) == 0 {
+			continue
+		}
+		sort.Slice(refs, func(i, j int) bool { return refs[i].Before(refs[j]) })
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+		deviceOffsets := estimateDeviceOffsets(deviceID, times, refs, params)
+		for _, offset := range deviceOffsets {
+			fmt.Printf("  %s: %s from %s to %s (%d matches, confidence %.2f)\n",
+				deviceID, FormatClockOffset(offset.OffsetSeconds),
+				offset.ValidFrom.Format("2006-01-02"), offset.ValidTo.Format("2006-01-02"),
+				offset.Matches, offset.Confidence)
+		}
+		offsets = append(offsets, deviceOffsets...)
+	}
+
+	return offsets
+}
+
+// estimateDeviceOffsets finds the offset of each time range of one device and merges
+// adjacent ranges that agree. times and refs must be sorted.
+func estimateDeviceOffsets(deviceID string, times, refs []time.Time, params ClockOffsetParams) []models.ClockOffset {
+	var offsets []models.ClockOffset
+
+	start := 0
+	for i := 1; i <= len(times); i++ {
+		if i < len(times) && times[i].Sub(times[i-1]) <= params.SegmentGap {
+			continue
+		}
+		segment := times[start:i]
+		start = i
+
+		if len(segment) < params.MinMatches {
+			continue
+		}
+
+		offset, matches, confidence := searchClockOffset(segment, refs, params)
+		if matches < params.MinMatches || absDuration(offset) < clockMinOffset {
+			continue
+		}
+		// The clock is only wrong if the offset explains the photos better than no offset
+		if matches <= countClockMatches(segment, refs, 0, params.Tolerance) {
+			continue
+		}
+
+		current := models.ClockOffset{
+			DeviceID:      deviceID,
+			ValidFrom:     segment[0],
+			ValidTo:       segment[len(segment)-1],
+			OffsetSeconds: int64(offset / time.Second),
+			Confidence:    confidence,
+			Matches:       matches,
+			Status:        models.ClockOffsetSuggested,
+		}
+
+		if n := len(offsets); n > 0 && absDuration(time.Duration(offsets[n-1].OffsetSeconds-current.OffsetSeconds)*time.Second) <= 2*clockFineStep {
+			prev := &offsets[n-1]
+			total := prev.Matches + current.Matches
+			prev.Confidence = (prev.Confidence*float64(prev.Matches) + current.Confidence*float64(current.Matches)) / float64(total)
+			prev.Matches = total
+			prev.ValidTo = current.ValidTo
+			continue
+		}
+		offsets = append(offsets, current)
+	}
+
+	return offsets
+}
+
+// searchClockOffset returns the offset that aligns the most photos with reference photos,
+// the number of aligned photos and a confidence score. Confidence is high when the best
+// offset clearly beats every offset more than an hour away and enough photos align.
+func searchClockOffset(times, refs []time.Time, params ClockOffsetParams) (time.Duration, int, float64) {
+	type candidate struct {
+		offset  time.Duration
+		matches int
+	}
+
+	var coarse []candidate
+	best := candidate{}
+	for offset := -params.MaxOffset; offset <= params.MaxOffset; offset += clockCoarseStep {
+		c := candidate{offset, countClockMatches(times, refs, offset, params.Tolerance)}
+		coarse = append(coarse, c)
+		if c.matches > best.matches || (c.matches == best.matches && absDuration(c.offset) < absDuration(best.offset)) {
+			best = c
+		}
+	}
+	if best.matches == 0 {
+		return 0, 0, 0
+	}
+
+	runnerUp := 0
+	for _, c := range coarse {
+		if absDuration(c.offset-best.offset) > time.Hour && c.matches > runnerUp {
+			runnerUp = c.matches
+		}
+	}
+
+	// Matches plateau across the tolerance window, so take the middle of the best run
+	var fine []candidate
+	for offset := best.offset - clockCoarseStep; offset <= best.offset+clockCoarseStep; offset += clockFineStep {
+		c := candidate{offset, countClockMatches(times, refs, offset, params.Tolerance)}
+		fine = append(fine, c)
+		if c.matches > best.matches {
+			best = c
+		}
+	}
+	first, last := -1, -1
+	for i, c := range fine {
+		if c.matches == best.matches {
+			if first == -1 {
+				first = i
+			}
+			last = i
+		} else if first != -1 {
+			break
+		}
+	}
+	if first != -1 {
+		best.offset = fine[(first+last)/2].offset
+	}
+
+	distinct := 1 - float64(runnerUp)/float64(best.matches)
+	sample := math.Min(1.0, float64(best.matches)/float64(4*params.MinMatches))
+	return best.offset, best.matches, math.Max(0, distinct) * sample
+}
+
+// countClockMatches counts photos that have a reference photo within tolerance once shifted by offset
+func countClockMatches(times, refs []time.Time, offset, tolerance time.Duration) int {
+	matches := 0
+	for _, t := range times {
+		shifted := t.Add(offset)
+		idx := sort.Search(len(refs), func(i int) bool { return !refs[i].Before(shifted) })
+		if idx < len(refs) && refs[idx].Sub(shifted) <= tolerance {
+			matches++
+		} else if idx > 0 && shifted.Sub(refs[idx-1]) <= tolerance {
+			matches++
+		}
+	}
+	return matches
+}
+
+// ApplyClockOffsets returns a copy of assets with accepted and manual clock offsets added
+// to their local time, and the number of assets corrected. Manual offsets take
+// precedence over accepted ones; suggested offsets are ignored.
+func ApplyClockOffsets(assets []models.Asset, devices []models.Device, offsets []models.ClockOffset) ([]models.Asset, int) {
+	byDevice := make(map[string][]models.ClockOffset)
+	for _, offset := range offsets {
+		if offset.Status == models.ClockOffsetAccepted || offset.Status == models.ClockOffsetManual {
+			byDevice[offset.DeviceID] = append(byDevice[offset.DeviceID], offset)
+		}
+	}
+
+	corrected := make([]models.Asset, len(assets))
+	copy(corrected, assets)
+	if len(byDevice) == 0 {
+		return corrected, 0
+	}
+
+	deviceMap := make(map[string]models.Device)
+	for _, device := range devices {
+		deviceMap[device.ID] = device
+	}
+
+	count := 0
+	for i, asset := range corrected {
+		deviceOffsets := byDevice[ResolveDevice(asset, deviceMap)]
+		if len(deviceOffsets) == 0 {
+			continue
+		}
+		if offset, ok := clockOffsetAt(deviceOffsets, asset.LocalDateTime); ok && offset != 0 {
+			corrected[i].LocalDateTime = asset.LocalDateTime.Add(offset)
+			count++
+		}
+	}
+
+	return corrected, count
+}
+
+// clockOffsetAt returns the offset covering t, preferring manual offsets
+func clockOffsetAt(offsets []models.ClockOffset, t time.Time) (time.Duration, bool) {
+	var found *models.ClockOffset
+	for i := range offsets {
+		offset := &offsets[i]
+		if !offset.ValidFrom.IsZero() && t.Before(offset.ValidFrom) {
+			continue
+		}
+		if !offset.ValidTo.IsZero() && t.After(offset.ValidTo) {
+			continue
+		}
+		if found == nil || (offset.Status == models.ClockOffsetManual && found.Status != models.ClockOffsetManual) {
+			found = offset
+		}
+	}
+	if found == nil {
+		return 0, false
+	}
+	return time.Duration(found.OffsetSeconds) * time.Second, true
+}
+
+// FormatClockOffset formats an offset in seconds as a signed duration like "+1h30m0s"
+func FormatClockOffset(seconds int64) string {
+	d := time.Duration(seconds) * time.Second
+	if d < 0 {
+		return "-" + (-d).String()
+	}
+	return "+" + d.String()
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
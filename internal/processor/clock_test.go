@@ -0,0 +1,216 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+var clockStart = time.Date(2024, 7, 1, 8, 0, 0, 0, time.UTC)
+
+// phoneTimes returns n photo times at irregular intervals so that no wrong offset lines
+// up many photos by chance
+func phoneTimes(start time.Time, n int) []time.Time {
+	var times []time.Time
+	t := start
+	for i := 0; i < n; i++ {
+		times = append(times, t)
+		t = t.Add(time.Duration(20+(i*37)%90) * time.Minute)
+	}
+	return times
+}
+
+// cameraTimes returns what a camera whose clock needs offset added shows at the given times
+func cameraTimes(times []time.Time, offset time.Duration) []time.Time {
+	shifted := make([]time.Time, len(times))
+	for i, t := range times {
+		shifted[i] = t.Add(-offset)
+	}
+	return shifted
+}
+
+// clockAssets returns assets taken at times on a device, with GPS if gps is set
+func clockAssets(deviceID string, times []time.Time, gps bool) []models.Asset {
+	lat, lon := 60.1699, 24.9384
+	var assets []models.Asset
+	for _, t := range times {
+		asset := models.Asset{ID: deviceID + t.Format(time.RFC3339), DeviceKey: deviceID, LocalDateTime: t}
+		if gps {
+			asset.Latitude, asset.Longitude = &lat, &lon
+		}
+		assets = append(assets, asset)
+	}
+	return assets
+}
+
+var clockDevices = []models.Device{
+	{ID: "phone", Photographer: "Alice"},
+	{ID: "camera", Photographer: "Alice"},
+	{ID: "other-phone", Photographer: "Bob"},
+}
+
+func TestSearchClockOffset(t *testing.T) {
+	refs := phoneTimes(clockStart, 30)
+	params := DefaultClockOffsetParams()
+
+	for _, want := range []time.Duration{2 * time.Hour, -7 * time.Hour, 45 * time.Minute, -3 * time.Minute} {
+		t.Run(FormatClockOffset(int64(want/time.Second)), func(t *testing.T) {
+			offset, matches, confidence := searchClockOffset(cameraTimes(refs, want), refs, params)
+			if offset != want {
+				t.Errorf("offset = %v, want %v", offset, want)
+			}
+			if matches != len(refs) {
+				t.Errorf("matches = %d, want %d", matches, len(refs))
+			}
+			if confidence < 0.5 {
+				t.Errorf("confidence = %.2f, want a clear winner", confidence)
+			}
+		})
+	}
+}
+
+func TestSearchClockOffsetWithoutReferences(t *testing.T) {
+	offset, matches, confidence := searchClockOffset(phoneTimes(clockStart, 10), nil, DefaultClockOffsetParams())
+	if offset != 0 || matches != 0 || confidence != 0 {
+		t.Errorf("got %v, %d, %.2f, want nothing", offset, matches, confidence)
+	}
+}
+
+func TestEstimateClockOffsets(t *testing.T) {
+	truth := phoneTimes(clockStart, 30)
+	params := DefaultClockOffsetParams()
+
+	tests := []struct {
+		name   string
+		assets []models.Asset
+		want   []time.Duration
+	}{
+		{
+			name:   "camera behind the phone",
+			assets: append(clockAssets("phone", truth, true), clockAssets("camera", cameraTimes(truth, 2*time.Hour), false)...),
+			want:   []time.Duration{2 * time.Hour},
+		},
+		{
+			name:   "camera ahead of the phone",
+			assets: append(clockAssets("phone", truth, true), clockAssets("camera", cameraTimes(truth, -90*time.Minute), false)...),
+			want:   []time.Duration{-90 * time.Minute},
+		},
+		{
+			name:   "correct clock",
+			assets: append(clockAssets("phone", truth, true), clockAssets("camera", truth, false)...),
+			want:   nil,
+		},
+		{
+			name:   "only another photographer's phone",
+			assets: append(clockAssets("other-phone", truth, true), clockAssets("camera", cameraTimes(truth, 2*time.Hour), false)...),
+			want:   nil,
+		},
+		{
+			name:   "too few photos",
+			assets: append(clockAssets("phone", truth, true), clockAssets("camera", cameraTimes(truth[:params.MinMatches-1], 2*time.Hour), false)...),
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offsets := EstimateClockOffsets(tt.assets, clockDevices, params)
+			if len(offsets) != len(tt.want) {
+				t.Fatalf("got %d offsets, want %d: %+v", len(offsets), len(tt.want), offsets)
+			}
+			for i, offset := range offsets {
+				if got := time.Duration(offset.OffsetSeconds) * time.Second; got != tt.want[i] {
+					t.Errorf("offset %d = %v, want %v", i, got, tt.want[i])
+				}
+				if offset.DeviceID != "camera" || offset.Status != models.ClockOffsetSuggested {
+					t.Errorf("offset %d = %+v, want a suggestion for the camera", i, offset)
+				}
+			}
+		})
+	}
+}
+
+func TestEstimateClockOffsetsSplitsRanges(t *testing.T) {
+	// The camera clock is 2 hours behind on one trip and 1 hour ahead on the next
+	first := phoneTimes(clockStart, 20)
+	second := phoneTimes(clockStart.AddDate(0, 0, 10), 20)
+
+	assets := clockAssets("phone", append(append([]time.Time(nil), first...), second...), true)
+	assets = append(assets, clockAssets("camera", cameraTimes(first, 2*time.Hour), false)...)
+	assets = append(assets, clockAssets("camera", cameraTimes(second, -time.Hour), false)...)
+
+	offsets := EstimateClockOffsets(assets, clockDevices, DefaultClockOffsetParams())
+	if len(offsets) != 2 {
+		t.Fatalf("got %d offsets, want 2: %+v", len(offsets), offsets)
+	}
+	if got := time.Duration(offsets[0].OffsetSeconds) * time.Second; got != 2*time.Hour {
+		t.Errorf("first offset = %v, want 2h", got)
+	}
+	if got := time.Duration(offsets[1].OffsetSeconds) * time.Second; got != -time.Hour {
+		t.Errorf("second offset = %v, want -1h", got)
+	}
+	if !offsets[0].ValidTo.Before(offsets[1].ValidFrom) {
+		t.Errorf("ranges overlap: %v-%v and %v-%v", offsets[0].ValidFrom, offsets[0].ValidTo, offsets[1].ValidFrom, offsets[1].ValidTo)
+	}
+}
+
+func TestClockOffsetAt(t *testing.T) {
+	day := func(d int) time.Time { return clockStart.AddDate(0, 0, d) }
+	ranged := models.ClockOffset{ValidFrom: day(10), ValidTo: day(20), OffsetSeconds: 3600, Status: models.ClockOffsetAccepted}
+	openEnded := models.ClockOffset{ValidFrom: day(15), OffsetSeconds: 600, Status: models.ClockOffsetManual}
+	unbounded := models.ClockOffset{OffsetSeconds: -60, Status: models.ClockOffsetAccepted}
+
+	tests := []struct {
+		name    string
+		offsets []models.ClockOffset
+		at      time.Time
+		want    time.Duration
+		wantOK  bool
+	}{
+		{"before range", []models.ClockOffset{ranged}, day(9), 0, false},
+		{"at range start", []models.ClockOffset{ranged}, day(10), time.Hour, true},
+		{"inside range", []models.ClockOffset{ranged}, day(12), time.Hour, true},
+		{"at range end", []models.ClockOffset{ranged}, day(20), time.Hour, true},
+		{"after range", []models.ClockOffset{ranged}, day(21), 0, false},
+		{"no upper bound", []models.ClockOffset{openEnded}, day(400), 10 * time.Minute, true},
+		{"no bounds", []models.ClockOffset{unbounded}, day(-400), -time.Minute, true},
+		{"manual wins where ranges overlap", []models.ClockOffset{ranged, openEnded}, day(16), 10 * time.Minute, true},
+		{"accepted applies outside the manual range", []models.ClockOffset{ranged, openEnded}, day(12), time.Hour, true},
+		{"first of equal offsets wins", []models.ClockOffset{ranged, unbounded}, day(12), time.Hour, true},
+		{"no offsets", nil, day(12), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := clockOffsetAt(tt.offsets, tt.at)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("clockOffsetAt = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestApplyClockOffsetsOnlyInsideRange(t *testing.T) {
+	day := func(d int) time.Time { return clockStart.AddDate(0, 0, d) }
+	assets := clockAssets("camera", []time.Time{day(1), day(5), day(9)}, false)
+	assets = append(assets, clockAssets("phone", []time.Time{day(5)}, true)...)
+	offsets := []models.ClockOffset{
+		{DeviceID: "camera", ValidFrom: day(4), ValidTo: day(6), OffsetSeconds: 7200, Status: models.ClockOffsetAccepted},
+		{DeviceID: "camera", ValidFrom: day(8), OffsetSeconds: 600, Status: models.ClockOffsetSuggested},
+	}
+
+	corrected, count := ApplyClockOffsets(assets, clockDevices, offsets)
+	if count != 1 {
+		t.Errorf("corrected %d assets, want 1", count)
+	}
+	want := []time.Time{day(1), day(5).Add(2 * time.Hour), day(9), day(5)}
+	for i, asset := range corrected {
+		if !asset.LocalDateTime.Equal(want[i]) {
+			t.Errorf("asset %d at %v, want %v", i, asset.LocalDateTime, want[i])
+		}
+	}
+	if !assets[1].LocalDateTime.Equal(day(5)) {
+		t.Errorf("input asset changed to %v", assets[1].LocalDateTime)
+	}
+}
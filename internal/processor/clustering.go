@@ -3,6 +3,8 @@ package processor
 import (
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/jamo/immich-albums/internal/models"
 )
@@ -13,8 +15,16 @@ type ClusteringParams struct {
 	MaxDistanceKM    float64 // Maximum distance between photos in same session
 	MinPhotosInSession int   // Minimum photos to form a session
 	MinConfidence    float64 // Minimum confidence for inferred locations
+	Algorithm        string  // AlgorithmLinear or AlgorithmSTDBSCAN
+	MinNeighbors     int     // ST-DBSCAN: photos within both limits needed to be a core photo
 }
 
+// Session clustering algorithms
+const (
+	AlgorithmLinear   = "linear"    // Break sessions at the first gap in time or distance
+	AlgorithmSTDBSCAN = "st-dbscan" // Density-based, tolerates outlier photos
+)
+
 // DefaultClusteringParams returns sensible defaults
 func DefaultClusteringParams() ClusteringParams {
 	return ClusteringParams{
@@ -22,9 +32,35 @@ func DefaultClusteringParams() ClusteringParams {
 		MaxDistanceKM:      5.0,  // 5 km
 		MinPhotosInSession: 2,    // At least 2 photos
 		MinConfidence:      0.3,  // Accept moderate confidence locations
+		Algorithm:          AlgorithmLinear,
+		MinNeighbors:       3,
+	}
+}
+
+// SessionClusterer groups one photographer's located photos, sorted by time, into sessions.
+// Photos that don't fit any session are returned as outliers.
+type SessionClusterer interface {
+	Cluster(assets []AssetWithLocation, photographer string) (sessions []models.Session, outliers []AssetWithLocation)
+}
+
+// NewSessionClusterer returns the clusterer for params.Algorithm
+func NewSessionClusterer(params ClusteringParams) (SessionClusterer, error) {
+	switch params.Algorithm {
+	case AlgorithmLinear, "":
+		return LinearClusterer{Params: params}, nil
+	case AlgorithmSTDBSCAN:
+		return STDBSCANClusterer{Params: params}, nil
+	default:
+		return nil, fmt.Errorf("unknown clustering algorithm %q (expected %q or %q)", params.Algorithm, AlgorithmLinear, AlgorithmSTDBSCAN)
 	}
 }
 
+// SessionOutlier is a located photo left out of every session
+type SessionOutlier struct {
+	Photographer string
+	Asset        AssetWithLocation
+}
+
 // AssetWithLocation wraps an asset with its effective location
 type AssetWithLocation struct {
 	Asset       models.Asset
@@ -34,8 +70,14 @@ type AssetWithLocation struct {
 	HasLocation bool
 }
 
-// DetectSessions groups photos into sessions based on time and location
-func DetectSessions(assets []models.Asset, inferences map[string]LocationInference, devices map[string]models.Device, params ClusteringParams) []models.Session {
+// DetectSessions groups photos into sessions based on time and location, and returns the
+// outlier photos the clusterer left out
+func DetectSessions(assets []models.Asset, inferences map[string]models.LocationInference, devices map[string]models.Device, params ClusteringParams) ([]models.Session, []SessionOutlier, error) {
+	clusterer, err := NewSessionClusterer(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Prepare assets with effective locations
 	fmt.Println("Filtering assets with valid locations...")
 	var located []AssetWithLocation
@@ -61,19 +103,23 @@ func DetectSessions(assets []models.Asset, inferences map[string]LocationInferen
 
 	fmt.Printf("Assets with valid locations: %d\n", len(located))
 
-	// Sort by time
+	// Sort by time, photos taken at the same moment by ID so runs are repeatable
 	sort.Slice(located, func(i, j int) bool {
-		return located[i].Asset.LocalDateTime.Before(located[j].Asset.LocalDateTime)
+		a, b := located[i].Asset, located[j].Asset
+		if !a.LocalDateTime.Equal(b.LocalDateTime) {
+			return a.LocalDateTime.Before(b.LocalDateTime)
+		}
+		return a.ID < b.ID
 	})
 
-	// Group by photographer - match assets to devices by make/model/filename pattern
+	// Group by photographer - resolve devices from the stored device key (or make/model/filename pattern)
 	photographerAssets := make(map[string][]AssetWithLocation)
 	for _, asset := range located {
 		if asset.Asset.Make == "" && asset.Asset.Model == "" {
 			continue // Skip assets without device info
 		}
-		// Find matching device for this asset
-		deviceID := findMatchingDeviceMap(asset.Asset, devices)
+		// Resolve device for this asset
+		deviceID := ResolveDevice(asset.Asset, devices)
 		if deviceID != "" {
 			if device, exists := devices[deviceID]; exists && device.Photographer != "" {
 				photographerAssets[device.Photographer] = append(photographerAssets[device.Photographer], asset)
@@ -85,24 +131,43 @@ func DetectSessions(assets []models.Asset, inferences map[string]LocationInferen
 
 	// Cluster each photographer's assets separately
 	var allSessions []models.Session
+	var allOutliers []SessionOutlier
 	fmt.Println("Clustering assets into sessions by photographer...")
-	photoCount := 0
-	for photographer, assets := range photographerAssets {
-		photoCount++
+	photographers := make([]string, 0, len(photographerAssets))
+	for photographer := range photographerAssets {
+		photographers = append(photographers, photographer)
+	}
+	sort.Strings(photographers)
+	for _, photographer := range photographers {
+		assets := photographerAssets[photographer]
 		fmt.Printf("  Processing %s (%d assets)...\n", photographer, len(assets))
-		sessions := clusterAssetsIntoSessions(assets, photographer, params)
+		sessions, outliers := clusterer.Cluster(assets, photographer)
 		fmt.Printf("    Found %d sessions\n", len(sessions))
+		if len(outliers) > 0 {
+			fmt.Printf("    Outliers: %d\n", len(outliers))
+		}
 		allSessions = append(allSessions, sessions...)
+		for _, outlier := range outliers {
+			allOutliers = append(allOutliers, SessionOutlier{Photographer: photographer, Asset: outlier})
+		}
 	}
 
 	fmt.Printf("Detected %d sessions\n", len(allSessions))
 
-	return allSessions
+	return allSessions, allOutliers, nil
 }
 
-func clusterAssetsIntoSessions(assets []AssetWithLocation, photographer string, params ClusteringParams) []models.Session {
+// LinearClusterer walks through the photos in time order and starts a new session as soon as
+// two consecutive photos are too far apart in time or distance. It reports no outliers.
+type LinearClusterer struct {
+	Params ClusteringParams
+}
+
+// Cluster implements SessionClusterer
+func (c LinearClusterer) Cluster(assets []AssetWithLocation, photographer string) ([]models.Session, []AssetWithLocation) {
+	params := c.Params
 	if len(assets) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	var sessions []models.Session
@@ -147,7 +212,7 @@ func clusterAssetsIntoSessions(assets []AssetWithLocation, photographer string,
 		sessions = append(sessions, session)
 	}
 
-	return sessions
+	return sessions, nil
 }
 
 func createSessionFromAssets(assets []AssetWithLocation, photographer string) models.Session {
@@ -190,112 +255,136 @@ func createSessionFromAssets(assets []AssetWithLocation, photographer string) mo
 
 // MergeSessions attempts to merge nearby sessions from different photographers
 // This is useful when multiple people take photos at the same event
+//
+// Two sessions are linked when they overlap or are at most maxTimeGapHours apart and their
+// centers are within maxDistanceKM, and linked sessions are merged transitively. Sessions
+// are put in a canonical order first, so the result doesn't depend on the input order.
 func MergeSessions(sessions []models.Session, maxTimeGapHours float64, maxDistanceKM float64) []models.Session {
 	const maxMergedSessionRadius = 50.0 // Maximum radius in km for a merged session
 	if len(sessions) <= 1 {
 		return sessions
 	}
 
-	// Sort by start time
-	sort.Slice(sessions, func(i, j int) bool {
-		return sessions[i].StartTime.Before(sessions[j].StartTime)
+	sorted := make([]models.Session, len(sessions))
+	copy(sorted, sessions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sessionLess(sorted[i], sorted[j])
 	})
 
-	var merged []models.Session
-	var currentGroup []models.Session
-	currentGroup = append(currentGroup, sessions[0])
-
-	for i := 1; i < len(sessions); i++ {
-		// Early termination: check time gap from earliest session in group
-		// If too large, no point checking others (sessions are time-sorted)
-		earliestEndTime := currentGroup[0].EndTime
-		timeGapFromEarliest := sessions[i].StartTime.Sub(earliestEndTime).Hours()
-
-		if timeGapFromEarliest > maxTimeGapHours {
-			// Time gap too large - finalize current group and start new one
-			if len(currentGroup) == 1 {
-				merged = append(merged, currentGroup[0])
-			} else {
-				mergedSession := combineSessionGroup(currentGroup)
-				merged = append(merged, mergedSession)
-			}
-			currentGroup = []models.Session{sessions[i]}
-			continue
+	// Union-find over the sessions, keeping the members of each group at its root
+	parent := make([]int, len(sorted))
+	members := make([][]int, len(sorted))
+	for i := range sorted {
+		parent[i] = i
+		members[i] = []int{i}
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
 		}
+		return parent[i]
+	}
 
-		// Check if current session can merge with any in the current group
-		canMerge := false
-		for _, groupSession := range currentGroup {
-			timeGap := sessions[i].StartTime.Sub(groupSession.EndTime).Hours()
-			distance := CalculateDistance(
-				groupSession.CenterLat, groupSession.CenterLon,
-				sessions[i].CenterLat, sessions[i].CenterLon,
-			)
-
-			if timeGap <= maxTimeGapHours && distance <= maxDistanceKM {
-				canMerge = true
-				break
+	maxGap := time.Duration(maxTimeGapHours * float64(time.Hour))
+	for i := range sorted {
+		// Sessions are sorted by start, so once one starts too late all later ones do too
+		for j := i + 1; j < len(sorted) && !sorted[j].StartTime.After(sorted[i].EndTime.Add(maxGap)); j++ {
+			distance := CalculateDistance(sorted[i].CenterLat, sorted[i].CenterLon, sorted[j].CenterLat, sorted[j].CenterLon)
+			if distance > maxDistanceKM {
+				continue
 			}
-		}
-
-		// Additional check: would the merged group exceed max radius?
-		if canMerge && len(currentGroup) > 0 {
-			// Calculate approximate merged radius
-			var sumLat, sumLon float64
-			for _, s := range currentGroup {
-				sumLat += s.CenterLat
-				sumLon += s.CenterLon
+			a, b := find(i), find(j)
+			if a == b {
+				continue
 			}
-			sumLat += sessions[i].CenterLat
-			sumLon += sessions[i].CenterLon
-			approxCenterLat := sumLat / float64(len(currentGroup)+1)
-			approxCenterLon := sumLon / float64(len(currentGroup)+1)
-
-			// Check if any session would be too far from the new center
-			maxDist := 0.0
-			for _, s := range currentGroup {
-				dist := CalculateDistance(approxCenterLat, approxCenterLon, s.CenterLat, s.CenterLon) + s.Radius
-				if dist > maxDist {
-					maxDist = dist
-				}
+			group := append(append([]int(nil), members[a]...), members[b]...)
+			if groupRadius(sorted, group) > maxMergedSessionRadius {
+				continue // Would make the session too dispersed
 			}
-			distNew := CalculateDistance(approxCenterLat, approxCenterLon, sessions[i].CenterLat, sessions[i].CenterLon) + sessions[i].Radius
-			if distNew > maxDist {
-				maxDist = distNew
+			// The smaller index stays the root so groups stay in canonical order
+			if b < a {
+				a, b = b, a
 			}
+			sort.Ints(group)
+			parent[b] = a
+			members[a], members[b] = group, nil
+		}
+	}
 
-			if maxDist > maxMergedSessionRadius {
-				canMerge = false // Would make the session too dispersed
-			}
+	var merged []models.Session
+	for i := range sorted {
+		if find(i) != i {
+			continue
 		}
+		if len(members[i]) == 1 {
+			merged = append(merged, sorted[i])
+			continue
+		}
+		group := make([]models.Session, len(members[i]))
+		for k, m := range members[i] {
+			group[k] = sorted[m]
+		}
+		merged = append(merged, combineSessionGroup(group))
+	}
 
-		if canMerge {
-			currentGroup = append(currentGroup, sessions[i])
-		} else {
-			// Finalize current group
-			if len(currentGroup) == 1 {
-				merged = append(merged, currentGroup[0])
-			} else {
-				mergedSession := combineSessionGroup(currentGroup)
-				merged = append(merged, mergedSession)
-			}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return sessionLess(merged[i], merged[j])
+	})
+	return merged
+}
 
-			// Start new group
-			currentGroup = []models.Session{sessions[i]}
+// sessionLess orders sessions by start, end, photographer and then asset IDs
+func sessionLess(a, b models.Session) bool {
+	if !a.StartTime.Equal(b.StartTime) {
+		return a.StartTime.Before(b.StartTime)
+	}
+	if !a.EndTime.Equal(b.EndTime) {
+		return a.EndTime.Before(b.EndTime)
+	}
+	if a.Photographer != b.Photographer {
+		return a.Photographer < b.Photographer
+	}
+	for k := 0; k < len(a.AssetIDs) && k < len(b.AssetIDs); k++ {
+		if a.AssetIDs[k] != b.AssetIDs[k] {
+			return a.AssetIDs[k] < b.AssetIDs[k]
 		}
 	}
+	return len(a.AssetIDs) < len(b.AssetIDs)
+}
 
-	// Don't forget the last group
-	if len(currentGroup) == 1 {
-		merged = append(merged, currentGroup[0])
-	} else if len(currentGroup) > 1 {
-		mergedSession := combineSessionGroup(currentGroup)
-		merged = append(merged, mergedSession)
+// groupCenter returns the mean center of a group of sessions
+func groupCenter(sessions []models.Session) (float64, float64) {
+	var sumLat, sumLon float64
+	for _, session := range sessions {
+		sumLat += session.CenterLat
+		sumLon += session.CenterLon
 	}
+	return sumLat / float64(len(sessions)), sumLon / float64(len(sessions))
+}
 
-	return merged
+// groupRadius returns the radius a session merged from the given sessions would have
+func groupRadius(sessions []models.Session, group []int) float64 {
+	selected := make([]models.Session, len(group))
+	for k, i := range group {
+		selected[k] = sessions[i]
+	}
+	centerLat, centerLon := groupCenter(selected)
+	return mergedRadius(selected, centerLat, centerLon)
 }
 
+func mergedRadius(sessions []models.Session, centerLat, centerLon float64) float64 {
+	maxRadius := 0.0
+	for _, session := range sessions {
+		dist := CalculateDistance(centerLat, centerLon, session.CenterLat, session.CenterLon) + session.Radius
+		if dist > maxRadius {
+			maxRadius = dist
+		}
+	}
+	return maxRadius
+}
+
+// combineSessionGroup merges sessions into one with sorted asset IDs and photographers
 func combineSessionGroup(sessions []models.Session) models.Session {
 	// Find overall time bounds
 	startTime := sessions[0].StartTime
@@ -303,10 +392,8 @@ func combineSessionGroup(sessions []models.Session) models.Session {
 
 	// Combine all asset IDs (use map for deduplication)
 	assetIDSet := make(map[string]bool)
-	photographers := make(map[string]bool)
-
-	var sumLat, sumLon float64
-	count := 0
+	photographerSet := make(map[string]bool)
+	var attachments []models.SessionAttachment
 
 	for _, session := range sessions {
 		if session.StartTime.Before(startTime) {
@@ -316,45 +403,34 @@ func combineSessionGroup(sessions []models.Session) models.Session {
 			endTime = session.EndTime
 		}
 
-		// Add asset IDs to set (automatically deduplicates)
 		for _, assetID := range session.AssetIDs {
 			assetIDSet[assetID] = true
 		}
-		photographers[session.Photographer] = true
-
-		sumLat += session.CenterLat
-		sumLon += session.CenterLon
-		count++
+		for _, p := range SessionPhotographers(session) {
+			if p != "" {
+				photographerSet[p] = true
+			}
+		}
+		attachments = append(attachments, session.Attachments...)
 	}
 
-	// Convert asset ID set back to slice
 	allAssetIDs := make([]string, 0, len(assetIDSet))
 	for assetID := range assetIDSet {
 		allAssetIDs = append(allAssetIDs, assetID)
 	}
+	sort.Strings(allAssetIDs)
 
-	// Calculate new center
-	centerLat := sumLat / float64(count)
-	centerLon := sumLon / float64(count)
-
-	// Calculate new radius
-	maxRadius := 0.0
-	for _, session := range sessions {
-		dist := CalculateDistance(centerLat, centerLon, session.CenterLat, session.CenterLon) + session.Radius
-		if dist > maxRadius {
-			maxRadius = dist
-		}
+	photographers := make([]string, 0, len(photographerSet))
+	for p := range photographerSet {
+		photographers = append(photographers, p)
 	}
+	sort.Strings(photographers)
 
-	// Create photographer list
-	var photographerList string
-	for p := range photographers {
-		if photographerList == "" {
-			photographerList = p
-		} else {
-			photographerList += ", " + p
-		}
-	}
+	sort.SliceStable(attachments, func(i, j int) bool {
+		return attachments[i].AssetID < attachments[j].AssetID
+	})
+
+	centerLat, centerLon := groupCenter(sessions)
 
 	return models.Session{
 		StartTime:    startTime,
@@ -362,7 +438,8 @@ func combineSessionGroup(sessions []models.Session) models.Session {
 		AssetIDs:     allAssetIDs,
 		CenterLat:    centerLat,
 		CenterLon:    centerLon,
-		Radius:       maxRadius,
-		Photographer: photographerList,
+		Radius:       mergedRadius(sessions, centerLat, centerLon),
+		Photographer: strings.Join(photographers, ", "),
+		Attachments:  attachments,
 	}
 }
@@ -0,0 +1,136 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// assertGolden compares got with testdata/name, or rewrites the file with -update
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n%s", path, got)
+	}
+}
+
+func marshalSessions(t *testing.T, sessions []models.Session) []byte {
+	t.Helper()
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(data, '\n')
+}
+
+// permutations returns the input in several different orders
+func permutations[T any](items []T) [][]T {
+	reversed := make([]T, len(items))
+	for i, item := range items {
+		reversed[len(items)-1-i] = item
+	}
+	result := [][]T{items, reversed}
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 5; n++ {
+		shuffled := append([]T(nil), items...)
+		rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		result = append(result, shuffled)
+	}
+	return result
+}
+
+func mergeFixture() []models.Session {
+	t0 := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	at := func(h float64) time.Time { return t0.Add(time.Duration(h * float64(time.Hour))) }
+	return []models.Session{
+		// A chain in Helsinki: Anna links to Ben and Ben to Cleo, but Anna and Cleo are 3 hours apart
+		{Photographer: "Anna", StartTime: at(0), EndTime: at(1), AssetIDs: []string{"a2", "a1"}, CenterLat: 60.1699, CenterLon: 24.9384, Radius: 0.5},
+		{Photographer: "Ben", StartTime: at(2), EndTime: at(3), AssetIDs: []string{"b1", "b2", "a1"}, CenterLat: 60.1710, CenterLon: 24.9400, Radius: 0.3},
+		{Photographer: "Cleo", StartTime: at(4), EndTime: at(5), AssetIDs: []string{"c1"}, CenterLat: 60.1690, CenterLon: 24.9410, Radius: 0.2},
+		// Same time, but in Tampere
+		{Photographer: "Dan", StartTime: at(0.5), EndTime: at(2), AssetIDs: []string{"d1", "d2"}, CenterLat: 61.4978, CenterLon: 23.7610, Radius: 1},
+		// Overlapping sessions in Turku, one of them already merged
+		{Photographer: "Ben, Anna", StartTime: at(24), EndTime: at(26), AssetIDs: []string{"e2", "e1"}, CenterLat: 60.4518, CenterLon: 22.2666, Radius: 0.4},
+		{Photographer: "Dan", StartTime: at(25), EndTime: at(25.5), AssetIDs: []string{"f1"}, CenterLat: 60.4520, CenterLon: 22.2670, Radius: 0.1},
+		// Helsinki again, but a day later
+		{Photographer: "Cleo", StartTime: at(48), EndTime: at(49), AssetIDs: []string{"g1", "g2"}, CenterLat: 60.1699, CenterLon: 24.9384, Radius: 0.5},
+	}
+}
+
+func TestMergeSessionsGolden(t *testing.T) {
+	var first []byte
+	for i, sessions := range permutations(mergeFixture()) {
+		got := marshalSessions(t, MergeSessions(sessions, 2, 1))
+		if i == 0 {
+			first = got
+			assertGolden(t, "merge_sessions.golden", got)
+			continue
+		}
+		if !bytes.Equal(got, first) {
+			t.Errorf("permutation %d merged differently:\n%s", i, got)
+		}
+	}
+}
+
+func TestDetectSessionsGolden(t *testing.T) {
+	t0 := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	devices := map[string]models.Device{
+		"phone-a": {ID: "phone-a", Photographer: "Anna"},
+		"phone-b": {ID: "phone-b", Photographer: "Ben"},
+	}
+	var assets []models.Asset
+	add := func(id, device string, minutes int, lat, lon float64) {
+		assets = append(assets, models.Asset{
+			ID: id, DeviceKey: device, Make: "Apple", Model: "iPhone",
+			LocalDateTime: t0.Add(time.Duration(minutes) * time.Minute),
+			Latitude:      &lat, Longitude: &lon,
+		})
+	}
+	for i, id := range []string{"a1", "a2", "a3", "a4"} {
+		add(id, "phone-a", i*20, 60.1699, 24.9384)
+	}
+	for i, id := range []string{"b1", "b2", "b3"} {
+		add(id, "phone-b", 10+i*20, 60.1705, 24.9390)
+	}
+	add("b4", "phone-b", 10, 60.1705, 24.9390) // Same moment as b1
+	for i, id := range []string{"a5", "a6"} {
+		add(id, "phone-a", 24*60+i*30, 61.4978, 23.7610)
+	}
+
+	params := DefaultClusteringParams()
+	var first []byte
+	for i, shuffled := range permutations(assets) {
+		sessions, _, err := DetectSessions(shuffled, nil, devices, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := marshalSessions(t, MergeSessions(sessions, 2, 1))
+		if i == 0 {
+			first = got
+			assertGolden(t, "detect_sessions.golden", got)
+			continue
+		}
+		if !bytes.Equal(got, first) {
+			t.Errorf("permutation %d detected differently:\n%s", i, got)
+		}
+	}
+}
@@ -171,17 +171,16 @@ func identifySubDevices(makeModel string, assets []models.Asset) []models.Device
 			deviceID = fmt.Sprintf("%s-device%d", makeModel, i+1)
 		}
 
-		// Store counter range for this device (for matching assets later)
-		deviceCounterRanges[deviceID] = struct{ min, max int }{
-			min: cluster.minCounter,
-			max: cluster.maxCounter,
-		}
-
+		// Keep the counter range on the device (persisted, used for matching assets later)
 		devices = append(devices, models.Device{
 			ID:         deviceID,
 			Make:       cluster.assets[0].Make,
 			Model:      cluster.assets[0].Model,
 			PhotoCount: len(cluster.assets),
+			CounterRange: &models.CounterRange{
+				Min: cluster.minCounter,
+				Max: cluster.maxCounter,
+			},
 		})
 	}
 
@@ -204,23 +203,22 @@ func max(a, b int) int {
 	return b
 }
 
+// filenameCounterPatterns are the common filename patterns holding a numeric counter,
+// compiled once: IMG_XXXX, DSC_XXXX, _MG_XXXX, etc.
+var filenameCounterPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`IMG_(\d+)`),
+	regexp.MustCompile(`DSC_(\d+)`),
+	regexp.MustCompile(`_MG_(\d+)`),
+	regexp.MustCompile(`DSCF(\d+)`),
+	regexp.MustCompile(`P\d+_(\d+)`),
+	regexp.MustCompile(`PXL_(\d{8})_(\d{6})`), // Pixel phones
+	regexp.MustCompile(`(\d{8}_\d{6})`),       // Generic timestamp pattern
+}
+
 // extractFilenameCounter extracts numeric counter from common filename patterns
-// Examples: IMG_1234.jpg -> 1234, DSC_5678.NEF -> 5678, PXL_20240101_123456.jpg -> 20240101123456
+// Examples: IMG_1234.jpg -> 1234, DSC_5678.NEF -> 5678, PXL_20240101_123456.jpg -> 20240101
 func extractFilenameCounter(filename string) (int, bool) {
-	// Common patterns:
-	// IMG_XXXX, DSC_XXXX, _MG_XXXX, etc.
-	patterns := []string{
-		`IMG_(\d+)`,
-		`DSC_(\d+)`,
-		`_MG_(\d+)`,
-		`DSCF(\d+)`,
-		`P\d+_(\d+)`,
-		`PXL_(\d{8})_(\d{6})`, // Pixel phones
-		`(\d{8}_\d{6})`, // Generic timestamp pattern
-	}
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
+	for _, re := range filenameCounterPatterns {
 		matches := re.FindStringSubmatch(filename)
 		if len(matches) > 1 {
 			// Use first captured group
@@ -233,23 +231,44 @@ func extractFilenameCounter(filename string) (int, bool) {
 	return 0, false
 }
 
-// deviceCounterRanges stores the counter ranges for each device (populated during discovery)
-var deviceCounterRanges = make(map[string]struct{ min, max int })
-
-// FindMatchingDevice finds the correct device ID for an asset
-// When there are multiple sub-devices (e.g., apple-iphone 13-device1, apple-iphone 13-device2),
-// we match based on the filename counter range
-func FindMatchingDevice(asset models.Asset, devices []models.Device) string {
-	// Convert slice to map for easier lookup
+// AssignDevices resolves the device ID of every asset that has make/model information.
+// The result maps asset ID to device ID and is stored as the assets' device key.
+func AssignDevices(assets []models.Asset, devices []models.Device) map[string]string {
 	deviceMap := make(map[string]models.Device)
 	for _, d := range devices {
 		deviceMap[d.ID] = d
 	}
 
-	return findMatchingDeviceMap(asset, deviceMap)
+	assignments := make(map[string]string, len(assets))
+	for _, asset := range assets {
+		if asset.Make == "" && asset.Model == "" {
+			continue
+		}
+		if deviceID := findMatchingDeviceMap(asset, deviceMap); deviceID != "" {
+			assignments[asset.ID] = deviceID
+		}
+	}
+
+	return assignments
+}
+
+// ResolveDevice returns the device ID for an asset, using the device key stored at
+// discovery time when it still refers to a known device and matching otherwise
+func ResolveDevice(asset models.Asset, devices map[string]models.Device) string {
+	if asset.DeviceKey != "" {
+		if _, exists := devices[asset.DeviceKey]; exists {
+			return asset.DeviceKey
+		}
+	}
+	if asset.Make == "" && asset.Model == "" {
+		return ""
+	}
+	return findMatchingDeviceMap(asset, devices)
 }
 
-// findMatchingDeviceMap is the internal version that works with a map
+// findMatchingDeviceMap finds the correct device ID for an asset by make and model
+// When there are multiple sub-devices (e.g., apple-iphone 13-device1, apple-iphone 13-device2),
+// we match based on the filename counter range
 func findMatchingDeviceMap(asset models.Asset, devices map[string]models.Device) string {
 	baseDeviceID := makeDeviceID(asset.Make, asset.Model)
 
@@ -258,37 +277,50 @@ func findMatchingDeviceMap(asset models.Asset, devices map[string]models.Device)
 		return baseDeviceID
 	}
 
+	// Collect sub-devices in a stable order so assignment never depends on map iteration
+	var candidates []models.Device
+	for deviceID, device := range devices {
+		if strings.HasPrefix(deviceID, baseDeviceID+"-device") {
+			candidates = append(candidates, device)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].ID < candidates[j].ID
+	})
+
 	// Extract counter from asset filename
 	counter, hasCounter := extractFilenameCounter(asset.OriginalFileName)
 	if !hasCounter {
 		// No counter, assign to first matching device
-		for deviceID := range devices {
-			if strings.HasPrefix(deviceID, baseDeviceID+"-device") {
-				return deviceID
-			}
-		}
-		return ""
+		return candidates[0].ID
 	}
 
-	// Find device whose counter range contains this asset's counter
-	for deviceID := range devices {
-		if strings.HasPrefix(deviceID, baseDeviceID+"-device") {
-			if counterRange, exists := deviceCounterRanges[deviceID]; exists {
-				// Check if counter falls within this device's range (with some tolerance)
-				tolerance := (counterRange.max - counterRange.min) / 4 // 25% tolerance
-				if counter >= counterRange.min-tolerance && counter <= counterRange.max+tolerance {
-					return deviceID
-				}
-			}
+	// Prefer the device whose counter range contains this asset's counter,
+	// otherwise the one whose range is closest
+	bestID := ""
+	bestDistance := 0
+	for _, device := range candidates {
+		if device.CounterRange == nil {
+			continue
 		}
-	}
-
-	// Fallback: return first matching device
-	for deviceID := range devices {
-		if strings.HasPrefix(deviceID, baseDeviceID+"-device") {
-			return deviceID
+		distance := 0
+		if counter < device.CounterRange.Min {
+			distance = device.CounterRange.Min - counter
+		} else if counter > device.CounterRange.Max {
+			distance = counter - device.CounterRange.Max
 		}
+		if bestID == "" || distance < bestDistance {
+			bestID = device.ID
+			bestDistance = distance
+		}
+	}
+	if bestID != "" {
+		return bestID
 	}
 
-	return ""
+	// Fallback: no stored ranges, return first matching device
+	return candidates[0].ID
 }
@@ -0,0 +1,180 @@
+package processor
+
+import (
+	"math"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// PolygonContains reports whether a point is inside a polygon's outline and outside its holes
+func PolygonContains(p models.Polygon, lat, lon float64) bool {
+	if len(p) == 0 || !ringContains(p[0], lat, lon) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if ringContains(hole, lat, lon) {
+			return false
+		}
+	}
+	return true
+}
+
+// polygonEdgeTolerance is how close in degrees a point must be to an edge to be on it
+const polygonEdgeTolerance = 1e-9
+
+// ringContains is the even-odd ray casting test on a ring of [longitude, latitude] positions.
+// Points on an edge are inside. Rings crossing the antimeridian are unwrapped past ±180, so
+// the point is also tried a full turn east and west.
+func ringContains(ring [][2]float64, lat, lon float64) bool {
+	ring = unwrapRing(ring)
+	for _, shift := range []float64{0, 360, -360} {
+		if rayCast(ring, lat, lon+shift) {
+			return true
+		}
+	}
+	return false
+}
+
+func rayCast(ring [][2]float64, lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if distanceToSegment(xi-lon, yi-lat, xj-lon, yj-lat) <= polygonEdgeTolerance {
+			return true
+		}
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// unwrapRing returns a copy of a ring whose longitudes never jump more than 180 degrees
+// between positions, so a ring crossing the antimeridian continues past ±180
+func unwrapRing(ring [][2]float64) [][2]float64 {
+	unwrapped := make([][2]float64, len(ring))
+	for i, pos := range ring {
+		if i > 0 {
+			pos[0] = unwrapped[i-1][0] + wrapLongitude(pos[0]-ring[i-1][0])
+		}
+		unwrapped[i] = pos
+	}
+	return unwrapped
+}
+
+// wrapLongitude returns a longitude, or a difference of two, in the range [-180, 180)
+func wrapLongitude(lon float64) float64 {
+	return math.Mod(math.Mod(lon+180, 360)+360, 360) - 180
+}
+
+// DistanceToPolygon returns the distance in km from a point to a polygon, 0 when inside.
+// Edges are measured on a local flat projection, which is accurate for home-sized areas.
+func DistanceToPolygon(p models.Polygon, lat, lon float64) float64 {
+	if len(p) == 0 {
+		return math.Inf(1)
+	}
+	if PolygonContains(p, lat, lon) {
+		return 0
+	}
+
+	kmPerLon := EarthRadiusKM * math.Pi / 180 * math.Cos(lat*math.Pi/180)
+	kmPerLat := EarthRadiusKM * math.Pi / 180
+	minDistance := math.Inf(1)
+	for _, ring := range p {
+		for i := range ring {
+			a, b := ring[i], ring[(i+1)%len(ring)]
+			// Measure from the point the short way around, and along the edge from there
+			dax := wrapLongitude(a[0] - lon)
+			dbx := dax + wrapLongitude(b[0]-a[0])
+			ax, ay := dax*kmPerLon, (a[1]-lat)*kmPerLat
+			bx, by := dbx*kmPerLon, (b[1]-lat)*kmPerLat
+			minDistance = math.Min(minDistance, distanceToSegment(ax, ay, bx, by))
+		}
+	}
+	return minDistance
+}
+
+// distanceToSegment returns the distance from the origin to the segment a-b
+func distanceToSegment(ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if length := dx*dx + dy*dy; length > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/length))
+	}
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
+
+// PolygonCenter returns the average position of a polygon's outline, used as the marker
+// position and for distances to far away sessions
+func PolygonCenter(p models.Polygon) (lat, lon float64) {
+	if len(p) == 0 || len(p[0]) == 0 {
+		return 0, 0
+	}
+	ring := p[0]
+	// GeoJSON rings repeat the first position at the end
+	if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+		ring = ring[:len(ring)-1]
+	}
+	for _, pos := range unwrapRing(ring) {
+		lon += pos[0]
+		lat += pos[1]
+	}
+	return lat / float64(len(ring)), wrapLongitude(lon / float64(len(ring)))
+}
+
+// HomeContains reports whether a point is inside a home: its area when it has one, otherwise
+// its radius
+func HomeContains(home models.HomeLocation, lat, lon float64) bool {
+	if len(home.Area) > 0 {
+		return PolygonContains(home.Area, lat, lon)
+	}
+	return CalculateDistance(lat, lon, home.Latitude, home.Longitude) <= home.Radius
+}
+
+// DistanceToHome returns the distance in km from a point to a home: to the edge of its area
+// when it has one, otherwise to its center
+func DistanceToHome(home models.HomeLocation, lat, lon float64) float64 {
+	if len(home.Area) > 0 {
+		return DistanceToPolygon(home.Area, lat, lon)
+	}
+	return CalculateDistance(lat, lon, home.Latitude, home.Longitude)
+}
+
+// ZoneContains reports whether a point is inside a zone: its area when it has one, otherwise
+// its radius
+func ZoneContains(zone models.Zone, lat, lon float64) bool {
+	if len(zone.Area) > 0 {
+		return PolygonContains(zone.Area, lat, lon)
+	}
+	return zone.Radius > 0 && CalculateDistance(lat, lon, zone.Latitude, zone.Longitude) <= zone.Radius
+}
+
+// ZoneAt returns the first zone containing a point, or nil
+func ZoneAt(zones []models.Zone, lat, lon float64) *models.Zone {
+	for i := range zones {
+		if ZoneContains(zones[i], lat, lon) {
+			return &zones[i]
+		}
+	}
+	return nil
+}
+
+// AreaHome creates a home location from a polygon, centered on it with a radius that covers
+// the whole outline for display
+func AreaHome(name string, area models.Polygon) models.HomeLocation {
+	lat, lon := PolygonCenter(area)
+	radius := 0.0
+	if len(area) > 0 {
+		for _, pos := range area[0] {
+			radius = math.Max(radius, CalculateDistance(lat, lon, pos[1], pos[0]))
+		}
+	}
+	return models.HomeLocation{
+		Name:      name,
+		Latitude:  lat,
+		Longitude: lon,
+		Radius:    math.Ceil(radius*10) / 10,
+		Area:      area,
+	}
+}
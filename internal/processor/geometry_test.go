@@ -0,0 +1,124 @@
+package processor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// Polygons are rings of [longitude, latitude] positions
+var (
+	square = models.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}
+	// Diamond with vertices on the axes, so rays from points on them pass through vertices
+	diamond = models.Polygon{{{0, -1}, {1, 0}, {0, 1}, {-1, 0}, {0, -1}}}
+	// L shape whose inner corner has a horizontal edge at latitude 2
+	lShape = models.Polygon{{{0, 0}, {4, 0}, {4, 2}, {2, 2}, {2, 4}, {0, 4}, {0, 0}}}
+	// Square with a square hole in the middle
+	holed = models.Polygon{
+		{{0, 0}, {3, 0}, {3, 3}, {0, 3}, {0, 0}},
+		{{1, 1}, {2, 1}, {2, 2}, {1, 2}, {1, 1}},
+	}
+	// Box from 179°E to 179°W across the antimeridian
+	antimeridian = models.Polygon{{{179, -1}, {-179, -1}, {-179, 1}, {179, 1}, {179, -1}}}
+)
+
+func TestPolygonContains(t *testing.T) {
+	tests := []struct {
+		name     string
+		polygon  models.Polygon
+		lat, lon float64
+		want     bool
+	}{
+		{"inside", square, 0.5, 0.5, true},
+		{"outside", square, 0.5, 1.5, false},
+		{"above", square, 1.5, 0.5, false},
+		{"bottom left vertex", square, 0, 0, true},
+		{"top right vertex", square, 1, 1, true},
+		{"left edge", square, 0.5, 0, true},
+		{"right edge", square, 0.5, 1, true},
+		{"bottom edge", square, 0, 0.5, true},
+		{"top edge", square, 1, 0.5, true},
+		{"ray through a vertex from inside", diamond, 0, -0.5, true},
+		{"ray through two vertices from outside", diamond, 0, -2, false},
+		{"ray through the top vertex", diamond, 1, -0.5, false},
+		{"ray along a horizontal edge from inside", lShape, 2, 1, true},
+		{"ray along a horizontal edge from outside", lShape, 2, -1, false},
+		{"on a horizontal edge", lShape, 2, 3, true},
+		{"in the notch", lShape, 3, 3, false},
+		{"between outline and hole", holed, 0.5, 1.5, true},
+		{"in the hole", holed, 1.5, 1.5, false},
+		{"on the hole's edge", holed, 1, 1.5, false},
+		{"antimeridian east side", antimeridian, 0, 179.5, true},
+		{"antimeridian west side", antimeridian, 0, -179.5, true},
+		{"antimeridian at 180", antimeridian, 0, 180, true},
+		{"antimeridian at -180", antimeridian, 0, -180, true},
+		{"antimeridian far away", antimeridian, 0, 0, false},
+		{"antimeridian just outside", antimeridian, 0, 178.5, false},
+		{"empty polygon", nil, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PolygonContains(tt.polygon, tt.lat, tt.lon); got != tt.want {
+				t.Errorf("PolygonContains(%v, %v) = %v, want %v", tt.lat, tt.lon, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistanceToPolygon(t *testing.T) {
+	kmPerDegree := EarthRadiusKM * math.Pi / 180
+
+	tests := []struct {
+		name     string
+		polygon  models.Polygon
+		lat, lon float64
+		want     float64
+	}{
+		{"inside", square, 0.5, 0.5, 0},
+		{"on a vertex", square, 1, 1, 0},
+		{"on an edge", square, 0, 0.5, 0},
+		{"north of an edge", square, 1.1, 0.5, 0.1 * kmPerDegree},
+		{"south of an edge", square, -0.1, 0.5, 0.1 * kmPerDegree},
+		{"beyond a vertex", square, -0.3, -0.4, 0.5 * kmPerDegree},
+		{"in the hole", holed, 1.5, 1.5, 0.5 * kmPerDegree},
+		{"across the antimeridian from the east", antimeridian, 0, -178.9, 0.1 * kmPerDegree},
+		{"across the antimeridian from the west", antimeridian, 0, 178.9, 0.1 * kmPerDegree},
+		{"north of an edge crossing the antimeridian", antimeridian, 1.1, 180, 0.1 * kmPerDegree},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DistanceToPolygon(tt.polygon, tt.lat, tt.lon)
+			// The flat projection shrinks longitude by the cosine of the latitude
+			if math.Abs(got-tt.want) > 0.01*tt.want+1e-9 {
+				t.Errorf("DistanceToPolygon(%v, %v) = %.3f km, want %.3f km", tt.lat, tt.lon, got, tt.want)
+			}
+		})
+	}
+
+	if got := DistanceToPolygon(nil, 0, 0); !math.IsInf(got, 1) {
+		t.Errorf("DistanceToPolygon(nil) = %v, want +Inf", got)
+	}
+}
+
+func TestPolygonCenter(t *testing.T) {
+	tests := []struct {
+		name     string
+		polygon  models.Polygon
+		lat, lon float64
+	}{
+		{"square", square, 0.5, 0.5},
+		{"antimeridian", antimeridian, 0, -180},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lat, lon := PolygonCenter(tt.polygon)
+			if math.Abs(lat-tt.lat) > 1e-9 || math.Abs(lon-tt.lon) > 1e-9 {
+				t.Errorf("PolygonCenter = %v, %v, want %v, %v", lat, lon, tt.lat, tt.lon)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package processor
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// HomeValidAt reports whether a home was lived in at time t
+func HomeValidAt(home models.HomeLocation, t time.Time) bool {
+	if !home.ValidFrom.IsZero() && t.Before(home.ValidFrom) {
+		return false
+	}
+	if !home.ValidTo.IsZero() && t.After(home.ValidTo) {
+		return false
+	}
+	return true
+}
+
+// HomesAt returns the homes that were lived in at time t
+func HomesAt(homes []models.HomeLocation, t time.Time) []models.HomeLocation {
+	var valid []models.HomeLocation
+	for _, home := range homes {
+		if HomeValidAt(home, t) {
+			valid = append(valid, home)
+		}
+	}
+	return valid
+}
+
+// HomesFor returns the homes of a photographer that were lived in at time t, including
+// homes shared by everyone
+func HomesFor(homes []models.HomeLocation, photographer string, t time.Time) []models.HomeLocation {
+	var valid []models.HomeLocation
+	for _, home := range homes {
+		if (home.Photographer == "" || home.Photographer == photographer) && HomeValidAt(home, t) {
+			valid = append(valid, home)
+		}
+	}
+	return valid
+}
+
+// SessionPhotographers returns the photographers of a session. Sessions merged across
+// photographers list them separated by commas.
+func SessionPhotographers(session models.Session) []string {
+	if session.Photographer == "" {
+		return []string{""}
+	}
+	photographers := strings.Split(session.Photographer, ",")
+	for i := range photographers {
+		photographers[i] = strings.TrimSpace(photographers[i])
+	}
+	return photographers
+}
+
+// IsAtHome reports whether a point is inside one of the photographer's homes at time t
+func IsAtHome(lat, lon float64, photographer string, t time.Time, homes []models.HomeLocation) bool {
+	for _, home := range HomesFor(homes, photographer, t) {
+		if HomeContains(home, lat, lon) {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionAtHome reports whether a session took place at the home of any of its photographers
+func SessionAtHome(session models.Session, homes []models.HomeLocation) bool {
+	for _, photographer := range SessionPhotographers(session) {
+		if IsAtHome(session.CenterLat, session.CenterLon, photographer, session.StartTime, homes) {
+			return true
+		}
+	}
+	return false
+}
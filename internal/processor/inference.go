@@ -16,18 +16,9 @@ const (
 	ProgressReportInterval     = 1000   // Report progress every N assets
 )
 
-// LocationInference contains inferred location data
-type LocationInference struct {
-	AssetID    string
-	Latitude   float64
-	Longitude  float64
-	Confidence float64
-	Source     string // "nearby", "interpolated", "same-session"
-	Method     string // Description of how it was inferred
-}
-
-// InferLocations processes assets and infers locations for those without GPS
-func InferLocations(assets []models.Asset, devices []models.Device) []LocationInference {
+// InferLocations processes assets and infers locations for those without GPS.
+// tracks holds imported track points per photographer, sorted by time; it may be nil.
+func InferLocations(assets []models.Asset, devices []models.Device, tracks map[string][]models.TrackPoint) []models.LocationInference {
 	// Create device map for quick lookup
 	deviceMap := make(map[string]models.Device)
 	for _, device := range devices {
@@ -64,8 +55,8 @@ func InferLocations(assets []models.Asset, devices []models.Device) []LocationIn
 		if gpsAsset.Make == "" && gpsAsset.Model == "" {
 			continue // Skip assets without device info
 		}
-		// Find matching device for this asset
-		deviceID := findMatchingDeviceMap(gpsAsset, deviceMap)
+		// Resolve device for this asset
+		deviceID := ResolveDevice(gpsAsset, deviceMap)
 		if deviceID != "" {
 			if gpsDevice, exists := deviceMap[deviceID]; exists && gpsDevice.Photographer != "" {
 				photographerGPS[gpsDevice.Photographer] = append(photographerGPS[gpsDevice.Photographer], gpsAsset)
@@ -73,8 +64,11 @@ func InferLocations(assets []models.Asset, devices []models.Device) []LocationIn
 		}
 	}
 	fmt.Printf("Found GPS data for %d photographers\n", len(photographerGPS))
+	if len(tracks) > 0 {
+		fmt.Printf("Found tracks for %d photographers\n", len(tracks))
+	}
 
-	var inferences []LocationInference
+	var inferences []models.LocationInference
 
 	fmt.Println("Processing assets...")
 	for i, asset := range withoutGPS {
@@ -86,8 +80,8 @@ func InferLocations(assets []models.Asset, devices []models.Device) []LocationIn
 		if asset.Make == "" && asset.Model == "" {
 			continue // Skip assets without device info
 		}
-		// Find matching device for this asset
-		deviceID := findMatchingDeviceMap(asset, deviceMap)
+		// Resolve device for this asset
+		deviceID := ResolveDevice(asset, deviceMap)
 		if deviceID == "" {
 			continue
 		}
@@ -96,14 +90,15 @@ func InferLocations(assets []models.Asset, devices []models.Device) []LocationIn
 			continue // Skip if device not labeled
 		}
 
-		// Get GPS assets for this photographer
-		gpsForPhotographer, hasGPS := photographerGPS[device.Photographer]
-		if !hasGPS || len(gpsForPhotographer) == 0 {
+		// Get GPS assets and track points for this photographer
+		gpsForPhotographer := photographerGPS[device.Photographer]
+		trackForPhotographer := tracks[device.Photographer]
+		if len(gpsForPhotographer) == 0 && len(trackForPhotographer) == 0 {
 			continue // No GPS data for this photographer
 		}
 
 		// Try to infer location
-		inference := inferSingleLocation(asset, device, gpsForPhotographer)
+		inference := inferSingleLocation(asset, device, gpsForPhotographer, trackForPhotographer)
 		if inference != nil {
 			inferences = append(inferences, *inference)
 		}
@@ -115,9 +110,14 @@ func InferLocations(assets []models.Asset, devices []models.Device) []LocationIn
 	return inferences
 }
 
-func inferSingleLocation(asset models.Asset, device models.Device, photographerGPS []models.Asset) *LocationInference {
+func inferSingleLocation(asset models.Asset, device models.Device, photographerGPS []models.Asset, track []models.TrackPoint) *models.LocationInference {
+	// Strategy 1: Position along an imported track
+	if onTrack := inferFromTrack(asset, track); onTrack != nil {
+		return onTrack
+	}
+
 	// GPS assets are already filtered for this photographer
-	// Strategy 1: Find nearest GPS photo in time
+	// Strategy 2: Find nearest GPS photo in time
 	nearest := findNearestInTime(asset, photographerGPS)
 	if nearest != nil {
 		timeDiff := math.Abs(asset.LocalDateTime.Sub(nearest.LocalDateTime).Hours())
@@ -126,7 +126,7 @@ func inferSingleLocation(asset models.Asset, device models.Device, photographerG
 		confidence := calculateTimeBasedConfidence(timeDiff)
 
 		if confidence > MinimumConfidenceThreshold { // Only accept if confidence is reasonable
-			return &LocationInference{
+			return &models.LocationInference{
 				AssetID:    asset.ID,
 				Latitude:   *nearest.Latitude,
 				Longitude:  *nearest.Longitude,
@@ -137,7 +137,7 @@ func inferSingleLocation(asset models.Asset, device models.Device, photographerG
 		}
 	}
 
-	// Strategy 2: Interpolation between two GPS photos
+	// Strategy 3: Interpolation between two GPS photos
 	interpolated := interpolateLocation(asset, photographerGPS)
 	if interpolated != nil {
 		return interpolated
@@ -181,7 +181,7 @@ func findNearestInTime(target models.Asset, candidates []models.Asset) *models.A
 	return nearest
 }
 
-func interpolateLocation(target models.Asset, gpsAssets []models.Asset) *LocationInference {
+func interpolateLocation(target models.Asset, gpsAssets []models.Asset) *models.LocationInference {
 	// Binary search to find insertion point (gpsAssets are sorted by time)
 	idx := sort.Search(len(gpsAssets), func(i int) bool {
 		return gpsAssets[i].LocalDateTime.After(target.LocalDateTime) ||
@@ -220,7 +220,7 @@ func interpolateLocation(target models.Asset, gpsAssets []models.Asset) *Locatio
 		return nil
 	}
 
-	return &LocationInference{
+	return &models.LocationInference{
 		AssetID:    target.ID,
 		Latitude:   lat,
 		Longitude:  lon,
@@ -230,6 +230,87 @@ func interpolateLocation(target models.Asset, gpsAssets []models.Asset) *Locatio
 	}
 }
 
+// inferFromTrack interpolates the asset's position between the track fixes around it.
+// Returns nil when the nearest fix is too far away in time to be trusted.
+func inferFromTrack(target models.Asset, track []models.TrackPoint) *models.LocationInference {
+	if len(track) == 0 {
+		return nil
+	}
+
+	// Binary search to find insertion point (track is sorted by time)
+	idx := sort.Search(len(track), func(i int) bool {
+		return !track[i].Time.Before(target.LocalDateTime)
+	})
+
+	var before, after *models.TrackPoint
+	if idx > 0 {
+		before = &track[idx-1]
+	}
+	if idx < len(track) {
+		after = &track[idx]
+	}
+
+	// Distance to the nearest fix decides the confidence
+	nearest := math.MaxFloat64
+	if before != nil {
+		nearest = target.LocalDateTime.Sub(before.Time).Minutes()
+	}
+	if after != nil {
+		nearest = math.Min(nearest, after.Time.Sub(target.LocalDateTime).Minutes())
+	}
+
+	confidence := calculateTrackConfidence(nearest)
+	if confidence == 0 {
+		return nil
+	}
+
+	inference := &models.LocationInference{
+		AssetID:    target.ID,
+		Confidence: confidence,
+		Source:     "track",
+	}
+
+	switch {
+	case before != nil && after != nil && after.Time.After(before.Time):
+		weight := target.LocalDateTime.Sub(before.Time).Seconds() / after.Time.Sub(before.Time).Seconds()
+		inference.Latitude = before.Latitude + (after.Latitude-before.Latitude)*weight
+		inference.Longitude = before.Longitude + (after.Longitude-before.Longitude)*weight
+		inference.Method = fmt.Sprintf("interpolated along track between fixes %.1f min before and %.1f min after",
+			target.LocalDateTime.Sub(before.Time).Minutes(), after.Time.Sub(target.LocalDateTime).Minutes())
+	case after != nil:
+		inference.Latitude = after.Latitude
+		inference.Longitude = after.Longitude
+		inference.Method = fmt.Sprintf("track fix %.1f min after", nearest)
+	default:
+		inference.Latitude = before.Latitude
+		inference.Longitude = before.Longitude
+		inference.Method = fmt.Sprintf("track fix %.1f min before", nearest)
+	}
+
+	return inference
+}
+
+// calculateTrackConfidence returns a confidence score (0-1) based on the minutes to the
+// nearest track fix, or 0 if the fix is too far away:
+// - < 5 minutes: 1.0
+// - 5-15 minutes: 0.95
+// - 15-60 minutes: 0.85
+// - 1-3 hours: 0.6
+func calculateTrackConfidence(minutesDiff float64) float64 {
+	switch {
+	case minutesDiff < 5:
+		return 1.0
+	case minutesDiff < 15:
+		return 0.95
+	case minutesDiff < 60:
+		return 0.85
+	case minutesDiff < 180:
+		return 0.6
+	default:
+		return 0
+	}
+}
+
 // calculateTimeBasedConfidence returns a confidence score (0-1) based on time gap
 // Confidence decay function:
 // - < 1 hour: 1.0 (very high confidence)
@@ -277,7 +358,7 @@ func CalculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
 }
 
 // GetEffectiveLocation returns the best available location for an asset
-func GetEffectiveLocation(asset models.Asset, inferences map[string]LocationInference) (lat, lon float64, hasLocation bool, confidence float64) {
+func GetEffectiveLocation(asset models.Asset, inferences map[string]models.LocationInference) (lat, lon float64, hasLocation bool, confidence float64) {
 	// Prefer original GPS data
 	if asset.Latitude != nil && asset.Longitude != nil {
 		return *asset.Latitude, *asset.Longitude, true, 1.0
@@ -0,0 +1,228 @@
+package processor
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// Place suggestion kinds
+const (
+	PlaceHome     = "home"     // Photographed at night on many days
+	PlaceFrequent = "frequent" // Visited on many days, mostly in daytime
+)
+
+// PlaceSuggestionParams controls how recurring places are found
+type PlaceSuggestionParams struct {
+	CellSize       float64 // km, grid cell size used to bucket photos
+	MergeDistance  float64 // km, busy cells closer than this form one place
+	MinDays        int     // minimum distinct days with photos for a suggestion
+	MinNightShare  float64 // share of days with night photos for a place to be a likely home
+	MaxSuggestions int
+}
+
+// DefaultPlaceSuggestionParams returns sensible defaults
+func DefaultPlaceSuggestionParams() PlaceSuggestionParams {
+	return PlaceSuggestionParams{
+		CellSize:       0.5,
+		MergeDistance:  1.5,
+		MinDays:        5,
+		MinNightShare:  0.25,
+		MaxSuggestions: 10,
+	}
+}
+
+// PlaceSuggestion is a recurring place that could be added as a home location
+type PlaceSuggestion struct {
+	Kind       string    `json:"kind"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Radius     float64   `json:"radius"` // km, covers most of the photos
+	PhotoCount int       `json:"photo_count"`
+	Days       int       `json:"days"`       // Distinct days with photos
+	NightDays  int       `json:"night_days"` // Distinct days with photos taken at night
+	FirstSeen  time.Time `json:"first_seen"`
+	LastSeen   time.Time `json:"last_seen"`
+	Score      float64   `json:"score"`
+}
+
+// placeCell collects the photos in one grid cell
+type placeCell struct {
+	photos    []placePhoto
+	days      map[string]bool
+	nightDays map[string]bool
+	lat, lon  float64 // Mean position
+}
+
+type placePhoto struct {
+	lat, lon float64
+	time     time.Time
+}
+
+// SuggestPlaces finds places photographed on many different days. Repeated days count
+// rather than photo volume, so a single busy event does not look like a home, and days with
+// night photos weigh double because people sleep at home. Places already covered by a home
+// location are skipped. Only original GPS coordinates are used.
+func SuggestPlaces(assets []models.Asset, homes []models.HomeLocation, params PlaceSuggestionParams) []PlaceSuggestion {
+	cellDeg := params.CellSize / 111.0
+	cells := make(map[[2]int]*placeCell)
+
+	for _, asset := range assets {
+		if asset.Latitude == nil || asset.Longitude == nil {
+			continue
+		}
+		lat, lon := *asset.Latitude, *asset.Longitude
+		if lat == 0 && lon == 0 {
+			continue
+		}
+
+		lonDeg := cellDeg / math.Max(math.Cos(lat*math.Pi/180), 0.01)
+		key := [2]int{int(math.Floor(lat / cellDeg)), int(math.Floor(lon / lonDeg))}
+		cell, ok := cells[key]
+		if !ok {
+			cell = &placeCell{days: make(map[string]bool), nightDays: make(map[string]bool)}
+			cells[key] = cell
+		}
+
+		t := asset.LocalDateTime
+		cell.photos = append(cell.photos, placePhoto{lat: lat, lon: lon, time: t})
+		// Photos after midnight belong to the previous evening, so every night day is a day too
+		night := isNightTime(t)
+		if night && t.Hour() < 12 {
+			t = t.AddDate(0, 0, -1)
+		}
+		day := t.Format("2006-01-02")
+		cell.days[day] = true
+		if night {
+			cell.nightDays[day] = true
+		}
+	}
+
+	var busy []*placeCell
+	for _, cell := range cells {
+		var sumLat, sumLon float64
+		for _, p := range cell.photos {
+			sumLat += p.lat
+			sumLon += p.lon
+		}
+		cell.lat = sumLat / float64(len(cell.photos))
+		cell.lon = sumLon / float64(len(cell.photos))
+		busy = append(busy, cell)
+	}
+
+	// Busiest cells first; ties broken by position for stable results
+	sort.Slice(busy, func(i, j int) bool {
+		si, sj := cellScore(busy[i]), cellScore(busy[j])
+		if si != sj {
+			return si > sj
+		}
+		if busy[i].lat != busy[j].lat {
+			return busy[i].lat < busy[j].lat
+		}
+		return busy[i].lon < busy[j].lon
+	})
+
+	// Grow places around the busiest cells, absorbing nearby cells
+	used := make([]bool, len(busy))
+	var suggestions []PlaceSuggestion
+	for i, seed := range busy {
+		if used[i] {
+			continue
+		}
+		place := &placeCell{days: make(map[string]bool), nightDays: make(map[string]bool)}
+		for j := i; j < len(busy); j++ {
+			if used[j] || CalculateDistance(seed.lat, seed.lon, busy[j].lat, busy[j].lon) > params.MergeDistance {
+				continue
+			}
+			used[j] = true
+			place.photos = append(place.photos, busy[j].photos...)
+			for day := range busy[j].days {
+				place.days[day] = true
+			}
+			for day := range busy[j].nightDays {
+				place.nightDays[day] = true
+			}
+		}
+
+		if len(place.days) < params.MinDays {
+			continue
+		}
+		suggestion := summarizePlace(place)
+		if coveredByHome(suggestion, homes) {
+			continue
+		}
+		if float64(suggestion.NightDays) >= params.MinNightShare*float64(suggestion.Days) {
+			suggestion.Kind = PlaceHome
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+	if params.MaxSuggestions > 0 && len(suggestions) > params.MaxSuggestions {
+		suggestions = suggestions[:params.MaxSuggestions]
+	}
+	return suggestions
+}
+
+// isNightTime reports whether a local time is between 21:00 and 06:00
+func isNightTime(t time.Time) bool {
+	return t.Hour() >= 21 || t.Hour() < 6
+}
+
+func cellScore(cell *placeCell) float64 {
+	return float64(len(cell.days) + len(cell.nightDays))
+}
+
+// summarizePlace computes the center, radius and date span of a place
+func summarizePlace(place *placeCell) PlaceSuggestion {
+	var sumLat, sumLon float64
+	first, last := place.photos[0].time, place.photos[0].time
+	for _, p := range place.photos {
+		sumLat += p.lat
+		sumLon += p.lon
+		if p.time.Before(first) {
+			first = p.time
+		}
+		if p.time.After(last) {
+			last = p.time
+		}
+	}
+	lat := sumLat / float64(len(place.photos))
+	lon := sumLon / float64(len(place.photos))
+
+	// Radius covering 90% of the photos, rounded up to 100 m
+	distances := make([]float64, len(place.photos))
+	for i, p := range place.photos {
+		distances[i] = CalculateDistance(lat, lon, p.lat, p.lon)
+	}
+	sort.Float64s(distances)
+	radius := distances[int(float64(len(distances)-1)*0.9)]
+	radius = math.Max(0.5, math.Ceil(radius*10)/10)
+
+	return PlaceSuggestion{
+		Kind:       PlaceFrequent,
+		Latitude:   lat,
+		Longitude:  lon,
+		Radius:     radius,
+		PhotoCount: len(place.photos),
+		Days:       len(place.days),
+		NightDays:  len(place.nightDays),
+		FirstSeen:  first,
+		LastSeen:   last,
+		Score:      float64(len(place.days) + len(place.nightDays)),
+	}
+}
+
+// coveredByHome reports whether a suggestion's center is inside an existing home
+func coveredByHome(suggestion PlaceSuggestion, homes []models.HomeLocation) bool {
+	for _, home := range homes {
+		if HomeContains(home, suggestion.Latitude, suggestion.Longitude) {
+			return true
+		}
+	}
+	return false
+}
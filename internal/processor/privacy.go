@@ -0,0 +1,66 @@
+package processor
+
+import (
+	"math"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// Ways of handling exported coordinates inside privacy zones
+const (
+	PrivacyRemove = "remove" // Drop the points
+	PrivacySnap   = "snap"   // Move the points to the center of a coarse grid cell
+)
+
+// PrivateAssets returns the IDs of assets located inside a privacy zone, by their original
+// GPS or inferred location
+func PrivateAssets(assets []models.Asset, inferences map[string]models.LocationInference, zones []models.Zone) map[string]bool {
+	private := make(map[string]bool)
+	if len(zones) == 0 {
+		return private
+	}
+	for _, asset := range assets {
+		lat, lon, ok, _ := GetEffectiveLocation(asset, inferences)
+		if ok && ZoneAt(zones, lat, lon) != nil {
+			private[asset.ID] = true
+		}
+	}
+	return private
+}
+
+// WithoutAssets returns the asset IDs that are not in the excluded set, keeping their order
+func WithoutAssets(assetIDs []string, excluded map[string]bool) []string {
+	kept := make([]string, 0, len(assetIDs))
+	for _, id := range assetIDs {
+		if !excluded[id] {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+
+// ApplyPrivacy removes track points inside privacy zones, or snaps them to the center of a
+// grid cell of gridKm so only the rough area is revealed
+func ApplyPrivacy(points []models.TrackPoint, zones []models.Zone, mode string, gridKm float64) []models.TrackPoint {
+	var result []models.TrackPoint
+	for _, point := range points {
+		if ZoneAt(zones, point.Latitude, point.Longitude) == nil {
+			result = append(result, point)
+			continue
+		}
+		if mode == PrivacySnap {
+			point.Latitude, point.Longitude = snapToGrid(point.Latitude, point.Longitude, gridKm)
+			result = append(result, point)
+		}
+	}
+	return result
+}
+
+// snapToGrid returns the center of the grid cell containing a point
+func snapToGrid(lat, lon, gridKm float64) (float64, float64) {
+	latDeg := gridKm / 111.0
+	lat = (math.Floor(lat/latDeg) + 0.5) * latDeg
+	lonDeg := latDeg / math.Max(math.Cos(lat*math.Pi/180), 0.01)
+	lon = (math.Floor(lon/lonDeg) + 0.5) * lonDeg
+	return lat, lon
+}
@@ -0,0 +1,164 @@
+package processor
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// FindRemovedAssets returns IDs of local assets that should no longer be used.
+// An asset is removed when Immich flags it as trashed or archived, or when it was taken
+// within [start, end) but is missing from a complete listing of that range.
+// Pass complete=false when remote is only a partial (incremental) listing.
+// Zero start or end leaves that side of the range open.
+func FindRemovedAssets(local []models.Asset, remote []models.Asset, start, end time.Time, complete bool) []string {
+	remoteIDs := make(map[string]bool, len(remote))
+	removed := make(map[string]bool)
+
+	for _, asset := range remote {
+		remoteIDs[asset.ID] = true
+		if asset.IsTrashed || asset.IsArchived {
+			removed[asset.ID] = true
+		}
+	}
+
+	var ids []string
+	for _, asset := range local {
+		if removed[asset.ID] {
+			ids = append(ids, asset.ID)
+			continue
+		}
+		if !complete || remoteIDs[asset.ID] {
+			continue
+		}
+		if !start.IsZero() && asset.FileCreatedAt.Before(start) {
+			continue
+		}
+		if !end.IsZero() && !asset.FileCreatedAt.Before(end) {
+			continue
+		}
+		ids = append(ids, asset.ID)
+	}
+
+	return ids
+}
+
+// PruneSessions removes deleted assets from sessions and recomputes the bounds, center and
+// radius of every session that lost assets. Members without a location and assets attached
+// by time are kept, with their attachment reasons, but the bounds, center and radius come
+// from the other members only. Sessions left with no located assets are dropped.
+// Returns the remaining sessions and the number of sessions that were changed or dropped.
+func PruneSessions(sessions []models.Session, removed map[string]bool, assets map[string]models.Asset, inferences map[string]models.LocationInference) ([]models.Session, int) {
+	var kept []models.Session
+	affected := 0
+
+	for _, session := range sessions {
+		attached := make(map[string]bool, len(session.Attachments))
+		for _, attachment := range session.Attachments {
+			attached[attachment.AssetID] = true
+		}
+
+		var located []AssetWithLocation
+		var unlocated []string
+		lost := false
+		for _, assetID := range session.AssetIDs {
+			if removed[assetID] {
+				lost = true
+				continue
+			}
+			asset, ok := assets[assetID]
+			if !ok {
+				lost = true
+				continue
+			}
+			lat, lon, hasLoc, conf := GetEffectiveLocation(asset, inferences)
+			if !hasLoc || attached[assetID] {
+				unlocated = append(unlocated, assetID)
+				continue
+			}
+			located = append(located, AssetWithLocation{
+				Asset:       asset,
+				Latitude:    lat,
+				Longitude:   lon,
+				Confidence:  conf,
+				HasLocation: true,
+			})
+		}
+
+		if !lost {
+			kept = append(kept, session)
+			continue
+		}
+
+		affected++
+		if len(located) == 0 {
+			continue
+		}
+
+		sort.Slice(located, func(i, j int) bool {
+			return located[i].Asset.LocalDateTime.Before(located[j].Asset.LocalDateTime)
+		})
+		pruned := createSessionFromAssets(located, session.Photographer)
+		pruned.ID = session.ID
+		pruned.AssetIDs = append(pruned.AssetIDs, unlocated...)
+
+		surviving := make(map[string]bool, len(unlocated))
+		for _, assetID := range unlocated {
+			surviving[assetID] = true
+		}
+		for _, attachment := range session.Attachments {
+			if surviving[attachment.AssetID] {
+				pruned.Attachments = append(pruned.Attachments, attachment)
+			}
+		}
+		kept = append(kept, pruned)
+	}
+
+	return kept, affected
+}
+
+// PruneTrips removes deleted assets from trips and recomputes the time bounds and session
+// count of the trips that lost assets. Trip sessions are expected to be pruned already.
+// It returns the trips that changed and the IDs of trips left empty.
+func PruneTrips(trips []models.Trip, removed map[string]bool, assets map[string]models.Asset) (changed []models.Trip, emptied []int64) {
+	for _, trip := range trips {
+		var remaining []string
+		for _, assetID := range trip.AssetIDs {
+			if !removed[assetID] {
+				remaining = append(remaining, assetID)
+			}
+		}
+
+		if len(remaining) == len(trip.AssetIDs) {
+			continue
+		}
+		if len(remaining) == 0 {
+			emptied = append(emptied, trip.ID)
+			continue
+		}
+
+		trip.AssetIDs = remaining
+		var start, end time.Time
+		for _, assetID := range remaining {
+			asset, ok := assets[assetID]
+			if !ok {
+				continue
+			}
+			if start.IsZero() || asset.LocalDateTime.Before(start) {
+				start = asset.LocalDateTime
+			}
+			if end.IsZero() || asset.LocalDateTime.After(end) {
+				end = asset.LocalDateTime
+			}
+		}
+		if !start.IsZero() {
+			trip.StartTime = start
+			trip.EndTime = end
+		}
+		trip.SessionCount = len(trip.Sessions)
+		changed = append(changed, trip)
+	}
+
+	return changed, emptied
+}
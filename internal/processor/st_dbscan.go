@@ -0,0 +1,102 @@
+package processor
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// STDBSCANClusterer groups photos with ST-DBSCAN: two photos are neighbors when they are
+// within both the time and the distance limit, photos with at least MinNeighbors neighbors
+// are core photos, and sessions are the photos reachable through chains of core photos.
+// A single photo with a bad GPS fix is left out as an outlier instead of splitting the
+// session it was taken in.
+type STDBSCANClusterer struct {
+	Params ClusteringParams
+}
+
+// Cluster implements SessionClusterer
+func (c STDBSCANClusterer) Cluster(assets []AssetWithLocation, photographer string) ([]models.Session, []AssetWithLocation) {
+	const (
+		unvisited = 0
+		noise     = -1
+	)
+
+	minNeighbors := c.Params.MinNeighbors
+	if minNeighbors < 1 {
+		minNeighbors = 1
+	}
+	maxGap := time.Duration(c.Params.MaxTimeGapHours * float64(time.Hour))
+
+	// neighbors returns the photos within both limits of photo i, including i itself. The
+	// photos are sorted by time, so only a window around i needs to be checked.
+	neighbors := func(i int) []int {
+		t := assets[i].Asset.LocalDateTime
+		from := sort.Search(len(assets), func(j int) bool {
+			return !assets[j].Asset.LocalDateTime.Before(t.Add(-maxGap))
+		})
+		var result []int
+		for j := from; j < len(assets) && !assets[j].Asset.LocalDateTime.After(t.Add(maxGap)); j++ {
+			if CalculateDistance(assets[i].Latitude, assets[i].Longitude, assets[j].Latitude, assets[j].Longitude) <= c.Params.MaxDistanceKM {
+				result = append(result, j)
+			}
+		}
+		return result
+	}
+
+	labels := make([]int, len(assets))
+	cluster := 0
+	for i := range assets {
+		if labels[i] != unvisited {
+			continue
+		}
+		seeds := neighbors(i)
+		if len(seeds) < minNeighbors {
+			labels[i] = noise
+			continue
+		}
+
+		cluster++
+		labels[i] = cluster
+		for k := 0; k < len(seeds); k++ {
+			j := seeds[k]
+			if labels[j] == noise {
+				labels[j] = cluster // Border photo
+			}
+			if labels[j] != unvisited {
+				continue
+			}
+			labels[j] = cluster
+			if next := neighbors(j); len(next) >= minNeighbors {
+				for _, n := range next {
+					if labels[n] == unvisited || labels[n] == noise {
+						seeds = append(seeds, n)
+					}
+				}
+			}
+		}
+	}
+
+	// Collect clusters in time order; labels grow with the time of their first photo
+	groups := make([][]AssetWithLocation, cluster+1)
+	var outliers []AssetWithLocation
+	for i, label := range labels {
+		if label == noise {
+			outliers = append(outliers, assets[i])
+			continue
+		}
+		groups[label] = append(groups[label], assets[i])
+	}
+
+	var sessions []models.Session
+	for _, group := range groups[1:] {
+		if len(group) < c.Params.MinPhotosInSession {
+			// Too small for a session, so reported with the outliers
+			outliers = append(outliers, group...)
+			continue
+		}
+		sessions = append(sessions, createSessionFromAssets(group, photographer))
+	}
+	return sessions, outliers
+}
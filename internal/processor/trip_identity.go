@@ -0,0 +1,155 @@
+package processor
+
+import (
+	"sort"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// Trip change kinds reported by MatchTrips
+const (
+	TripNew         = "new"
+	TripUnchanged   = "unchanged"
+	TripChanged     = "changed"
+	TripSplit       = "split"
+	TripMerged      = "merged"
+	TripDisappeared = "disappeared"
+)
+
+// TripChange describes how detected trips relate to previously stored trips
+type TripChange struct {
+	Kind     string
+	Trips    []models.Trip // Detected trips involved, empty for disappeared trips
+	Previous []models.Trip // Previous trips involved, empty for new trips
+}
+
+// MatchTrips gives newly detected trips the identity of the previous trips they overlap
+// most with, carrying over the ID, custom name, album exclusion and album ID. Each previous
+// trip is carried over to at most one detected trip. It returns the detected trips and the
+// changes compared to the previous trips.
+func MatchTrips(previous, detected []models.Trip) ([]models.Trip, []TripChange) {
+	assetTrip := make(map[string]int)
+	for i, trip := range previous {
+		for _, assetID := range trip.AssetIDs {
+			assetTrip[assetID] = i
+		}
+	}
+
+	// Count the assets each detected trip shares with each previous trip
+	type pair struct {
+		detected, previous, overlap int
+	}
+	var pairs []pair
+	overlapsByPrevious := make(map[int][]int)
+	overlapsByDetected := make(map[int][]int)
+	for d, trip := range detected {
+		counts := make(map[int]int)
+		for _, assetID := range trip.AssetIDs {
+			if p, ok := assetTrip[assetID]; ok {
+				counts[p]++
+			}
+		}
+		for p, overlap := range counts {
+			pairs = append(pairs, pair{d, p, overlap})
+			overlapsByPrevious[p] = append(overlapsByPrevious[p], d)
+			overlapsByDetected[d] = append(overlapsByDetected[d], p)
+		}
+	}
+
+	// Largest overlaps claim identities first, ties broken by position for stable results
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].overlap != pairs[j].overlap {
+			return pairs[i].overlap > pairs[j].overlap
+		}
+		if pairs[i].previous != pairs[j].previous {
+			return pairs[i].previous < pairs[j].previous
+		}
+		return pairs[i].detected < pairs[j].detected
+	})
+
+	matchedPrevious := make(map[int]int) // detected -> previous
+	claimed := make(map[int]bool)
+	for _, pr := range pairs {
+		if _, ok := matchedPrevious[pr.detected]; ok || claimed[pr.previous] {
+			continue
+		}
+		matchedPrevious[pr.detected] = pr.previous
+		claimed[pr.previous] = true
+	}
+
+	matched := make([]models.Trip, len(detected))
+	for d, trip := range detected {
+		trip.ID = 0
+		if p, ok := matchedPrevious[d]; ok {
+			prev := previous[p]
+			trip.ID = prev.ID
+			trip.AlbumID = prev.AlbumID
+			trip.ExcludeFromAlbum = prev.ExcludeFromAlbum
+			if prev.CustomName {
+				trip.Name = prev.Name
+				trip.CustomName = true
+			}
+		}
+		matched[d] = trip
+	}
+
+	var changes []TripChange
+	reported := make(map[int]bool) // detected trips already covered by a change
+	for p := range previous {
+		parts := overlapsByPrevious[p]
+		switch {
+		case len(parts) == 0:
+			changes = append(changes, TripChange{Kind: TripDisappeared, Previous: []models.Trip{previous[p]}})
+		case len(parts) > 1:
+			sort.Ints(parts)
+			change := TripChange{Kind: TripSplit, Previous: []models.Trip{previous[p]}}
+			for _, d := range parts {
+				change.Trips = append(change.Trips, matched[d])
+				reported[d] = true
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	for d := range detected {
+		sources := overlapsByDetected[d]
+		switch {
+		case len(sources) > 1:
+			sort.Ints(sources)
+			change := TripChange{Kind: TripMerged, Trips: []models.Trip{matched[d]}}
+			for _, p := range sources {
+				change.Previous = append(change.Previous, previous[p])
+			}
+			changes = append(changes, change)
+		case reported[d]:
+		case len(sources) == 0:
+			changes = append(changes, TripChange{Kind: TripNew, Trips: []models.Trip{matched[d]}})
+		default:
+			prev := previous[sources[0]]
+			kind := TripChanged
+			if sameAssets(prev.AssetIDs, detected[d].AssetIDs) {
+				kind = TripUnchanged
+			}
+			changes = append(changes, TripChange{Kind: kind, Trips: []models.Trip{matched[d]}, Previous: []models.Trip{prev}})
+		}
+	}
+
+	return matched, changes
+}
+
+// sameAssets reports whether two asset ID lists contain the same assets
+func sameAssets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, id := range a {
+		set[id] = true
+	}
+	for _, id := range b {
+		if !set[id] {
+			return false
+		}
+	}
+	return true
+}
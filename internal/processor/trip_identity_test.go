@@ -0,0 +1,136 @@
+package processor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+func idTrip(id int64, assetIDs ...string) models.Trip {
+	return models.Trip{ID: id, AssetIDs: assetIDs}
+}
+
+func TestMatchTrips(t *testing.T) {
+	tests := []struct {
+		name      string
+		previous  []models.Trip
+		detected  []models.Trip
+		wantIDs   []int64  // IDs of the detected trips after matching
+		wantKinds []string // Kinds of the reported changes, in order
+	}{
+		{
+			name:      "unchanged",
+			previous:  []models.Trip{idTrip(1, "a", "b")},
+			detected:  []models.Trip{idTrip(0, "b", "a")},
+			wantIDs:   []int64{1},
+			wantKinds: []string{TripUnchanged},
+		},
+		{
+			name:      "changed",
+			previous:  []models.Trip{idTrip(1, "a", "b")},
+			detected:  []models.Trip{idTrip(0, "a", "b", "c")},
+			wantIDs:   []int64{1},
+			wantKinds: []string{TripChanged},
+		},
+		{
+			name:      "new",
+			previous:  nil,
+			detected:  []models.Trip{idTrip(0, "a")},
+			wantIDs:   []int64{0},
+			wantKinds: []string{TripNew},
+		},
+		{
+			name:      "split keeps the identity on the larger part",
+			previous:  []models.Trip{idTrip(1, "a", "b", "c")},
+			detected:  []models.Trip{idTrip(0, "a"), idTrip(0, "b", "c")},
+			wantIDs:   []int64{0, 1},
+			wantKinds: []string{TripSplit},
+		},
+		{
+			name:      "merge keeps the identity of the larger trip",
+			previous:  []models.Trip{idTrip(1, "a"), idTrip(2, "b", "c")},
+			detected:  []models.Trip{idTrip(0, "a", "b", "c")},
+			wantIDs:   []int64{2},
+			wantKinds: []string{TripMerged},
+		},
+		{
+			name:      "disappear",
+			previous:  []models.Trip{idTrip(1, "a"), idTrip(2, "x", "y")},
+			detected:  []models.Trip{idTrip(0, "a")},
+			wantIDs:   []int64{1},
+			wantKinds: []string{TripDisappeared, TripUnchanged},
+		},
+		{
+			name:      "equal overlaps are broken by position",
+			previous:  []models.Trip{idTrip(1, "a", "b"), idTrip(2, "c", "d")},
+			detected:  []models.Trip{idTrip(0, "a", "b", "c", "d")},
+			wantIDs:   []int64{1},
+			wantKinds: []string{TripMerged},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, changes := MatchTrips(tt.previous, tt.detected)
+
+			var ids []int64
+			for _, trip := range matched {
+				ids = append(ids, trip.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("IDs = %v, want %v", ids, tt.wantIDs)
+			}
+
+			var kinds []string
+			for _, change := range changes {
+				kinds = append(kinds, change.Kind)
+			}
+			if !reflect.DeepEqual(kinds, tt.wantKinds) {
+				t.Errorf("changes = %v, want %v", kinds, tt.wantKinds)
+			}
+		})
+	}
+}
+
+func TestMatchTripsSplitReportsAllParts(t *testing.T) {
+	_, changes := MatchTrips(
+		[]models.Trip{idTrip(1, "a", "b", "c")},
+		[]models.Trip{idTrip(0, "a"), idTrip(0, "b"), idTrip(0, "c")},
+	)
+	if len(changes) != 1 || changes[0].Kind != TripSplit {
+		t.Fatalf("changes = %+v, want one split", changes)
+	}
+	if len(changes[0].Trips) != 3 || len(changes[0].Previous) != 1 {
+		t.Errorf("split has %d parts of %d trips, want 3 parts of 1", len(changes[0].Trips), len(changes[0].Previous))
+	}
+}
+
+func TestMatchTripsKeepsManualEdits(t *testing.T) {
+	previous := []models.Trip{
+		{ID: 7, Name: "Honeymoon", CustomName: true, AlbumID: "album-7", ExcludeFromAlbum: true, AssetIDs: []string{"a", "b"}},
+		{ID: 8, Name: "Tampere - Jun 1, 2024", AlbumID: "album-8", AssetIDs: []string{"c"}},
+	}
+	detected := []models.Trip{
+		{Name: "Paris, France - May 1-5, 2024", AssetIDs: []string{"a", "b", "x"}},
+		{Name: "Tampere, Finland - Jun 1, 2024", AssetIDs: []string{"c"}},
+	}
+
+	matched, _ := MatchTrips(previous, detected)
+
+	custom := matched[0]
+	if custom.ID != 7 || custom.Name != "Honeymoon" || !custom.CustomName {
+		t.Errorf("custom name not kept: %+v", custom)
+	}
+	if custom.AlbumID != "album-7" || !custom.ExcludeFromAlbum {
+		t.Errorf("album ID or exclusion not kept: %+v", custom)
+	}
+
+	generated := matched[1]
+	if generated.ID != 8 || generated.AlbumID != "album-8" {
+		t.Errorf("identity not kept: %+v", generated)
+	}
+	if generated.Name != "Tampere, Finland - Jun 1, 2024" || generated.CustomName {
+		t.Errorf("generated name should follow detection: %+v", generated)
+	}
+}
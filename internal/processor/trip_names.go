@@ -0,0 +1,192 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/i18n"
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// Trip categories, by length
+const (
+	TripDayTrip   = "day trip"   // Back the same day
+	TripWeekend   = "weekend"    // One or two nights over a weekend
+	TripShortTrip = "short trip" // Up to three nights
+	TripLongTrip  = "long trip"  // Four nights or more
+)
+
+// Built-in trip name templates, selectable by name
+var TripNameTemplates = map[string]string{
+	"default":   `{{with .Location}}{{.}}{{else}}{{t "trip"}}{{end}} - {{dates .Start .End}}`,
+	"route":     `{{if gt (len .Cities) 1}}{{routeMax 4 .Cities}}{{else}}{{with .Location}}{{.}}{{else}}{{t "trip"}}{{end}}{{end}} - {{dates .Start .End}}`,
+	"countries": `{{destination .}} - {{dates .Start .End}}`,
+}
+
+// TripNameData is what a trip name template can use
+type TripNameData struct {
+	Location      string        // Most photographed "City, Country"
+	Cities        []string      // In the order they were first visited
+	Countries     []string      // In the order they were first visited
+	Start         time.Time     // First photo, in local photo time
+	End           time.Time     // Last photo, in local photo time
+	Duration      time.Duration // From the first to the last photo
+	Days          int           // Calendar days
+	Nights        int           // Nights away
+	Photographers []string
+	Category      string // TripDayTrip, TripWeekend, TripShortTrip or TripLongTrip
+}
+
+// TripNamer names trips with a text/template
+type TripNamer struct {
+	tmpl *template.Template
+}
+
+// defaultTripNamer names trips during detection, before a configured template is applied
+var defaultTripNamer = func() *TripNamer {
+	namer, err := NewTripNamer("", i18n.English())
+	if err != nil {
+		panic(err)
+	}
+	return namer
+}()
+
+// NewTripNamer parses a trip name template. text is either the name of a built-in template
+// or the template itself; empty means the default template. Dates, lists and messages are
+// in the catalog's language.
+func NewTripNamer(text string, catalog *i18n.Catalog) (*TripNamer, error) {
+	if text == "" {
+		text = "default"
+	}
+	if builtin, ok := TripNameTemplates[text]; ok {
+		text = builtin
+	}
+	tmpl, err := template.New("trip name").Funcs(tripNameFuncs(catalog)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trip name template: %w", err)
+	}
+	// Catch unknown fields before any trip is named
+	if err := tmpl.Execute(io.Discard, TripNameData{}); err != nil {
+		return nil, fmt.Errorf("invalid trip name template: %w", err)
+	}
+	return &TripNamer{tmpl: tmpl}, nil
+}
+
+// Name returns the name of a trip. The trip's summary provides the cities and countries,
+// so it should be computed first.
+func (n *TripNamer) Name(trip models.Trip, assetMap map[string]models.Asset) (string, error) {
+	var b strings.Builder
+	if err := n.tmpl.Execute(&b, NewTripNameData(trip, assetMap)); err != nil {
+		return "", fmt.Errorf("failed to name trip: %w", err)
+	}
+	name := strings.Join(strings.Fields(b.String()), " ")
+	if name == "" {
+		return "", fmt.Errorf("trip name template gave an empty name for the trip starting %s", trip.StartTime.Format("2006-01-02"))
+	}
+	return name, nil
+}
+
+// NameTrips renames trips with a template, except those the user named by hand. Private
+// assets, see PrivateAssets, are left out so a name never gives away those places.
+func NameTrips(trips []models.Trip, assets []models.Asset, private map[string]bool, namer *TripNamer) error {
+	assetMap := make(map[string]models.Asset, len(assets))
+	for _, asset := range assets {
+		if !private[asset.ID] {
+			assetMap[asset.ID] = asset
+		}
+	}
+	for i := range trips {
+		if trips[i].CustomName {
+			continue
+		}
+		name, err := namer.Name(trips[i], assetMap)
+		if err != nil {
+			return err
+		}
+		trips[i].Name = name
+	}
+	return nil
+}
+
+// NewTripNameData collects what a name template can use about a trip
+func NewTripNameData(trip models.Trip, assetMap map[string]models.Asset) TripNameData {
+	days := int(calendarDay(trip.EndTime).Sub(calendarDay(trip.StartTime)).Hours()/24) + 1
+	nights := days - 1
+
+	var photographers []string
+	if trip.Photographers != "" {
+		photographers = SessionPhotographers(models.Session{Photographer: trip.Photographers})
+	}
+
+	return TripNameData{
+		Location:      extractLocationFromSessions(trip.Sessions, assetMap),
+		Cities:        trip.Summary.Cities,
+		Countries:     trip.Summary.Countries,
+		Start:         trip.StartTime,
+		End:           trip.EndTime,
+		Duration:      trip.EndTime.Sub(trip.StartTime),
+		Days:          days,
+		Nights:        nights,
+		Photographers: photographers,
+		Category:      tripCategory(trip.StartTime, nights),
+	}
+}
+
+// tripCategory classifies a trip by its length and, for short ones, by its weekdays
+func tripCategory(start time.Time, nights int) string {
+	switch {
+	case nights == 0:
+		return TripDayTrip
+	case nights <= 2 && (start.Weekday() == time.Friday || start.Weekday() == time.Saturday):
+		return TripWeekend
+	case nights <= 3:
+		return TripShortTrip
+	default:
+		return TripLongTrip
+	}
+}
+
+func tripNameFuncs(catalog *i18n.Catalog) template.FuncMap {
+	return template.FuncMap{
+		"route":    RouteString,
+		"routeMax": routeMax,
+		"list":     catalog.List,
+		"join":     func(sep string, items []string) string { return strings.Join(items, sep) },
+		"first":    func(items []string) string { return at(items, 0) },
+		"last":     func(items []string) string { return at(items, len(items)-1) },
+		"dates":    catalog.DateRange,
+		"date":     func(t time.Time) string { return catalog.FormatDate("date", t, t) },
+		"month":    func(t time.Time) string { return catalog.Months[t.Month()-1] },
+		"t":        func(key string) string { return catalog.T(key) },
+		"destination": func(data TripNameData) string {
+			switch {
+			case len(data.Countries) > 1:
+				return catalog.List(data.Countries)
+			case data.Location != "":
+				return data.Location
+			default:
+				return catalog.T("trip")
+			}
+		},
+	}
+}
+
+func at(items []string, i int) string {
+	if i < 0 || i >= len(items) {
+		return ""
+	}
+	return items[i]
+}
+
+// routeMax is a route of at most max places, keeping the first and the last, e.g.
+// "Paris → Lyon → … → Nice"
+func routeMax(max int, places []string) string {
+	if max < 2 || len(places) <= max {
+		return RouteString(places)
+	}
+	shortened := append(append([]string(nil), places[:max-1]...), "…", places[len(places)-1])
+	return RouteString(shortened)
+}
@@ -0,0 +1,235 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// tripGroup is a run of sessions that becomes a trip
+type tripGroup struct {
+	sessions []models.Session
+	reason   string // Why the trip ended, for progress output
+	manual   bool   // Shaped by a trip rule, kept regardless of size and duration
+}
+
+// NewMergeRule creates a rule merging two trips
+func NewMergeRule(a, b models.Trip) (models.TripRule, error) {
+	if a.ID == b.ID {
+		return models.TripRule{}, fmt.Errorf("cannot merge a trip with itself")
+	}
+	if len(a.AssetIDs) == 0 || len(b.AssetIDs) == 0 {
+		return models.TripRule{}, fmt.Errorf("cannot merge trips without photos")
+	}
+	return models.TripRule{
+		Type:          models.TripRuleMerge,
+		AssetID:       a.AssetIDs[0],
+		TargetAssetID: b.AssetIDs[0],
+		Description:   fmt.Sprintf("Merge %q and %q", a.Name, b.Name),
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// NewSplitRule creates a rule splitting a trip so that a new trip starts at the given
+// session, or at the given time when session is nil
+func NewSplitRule(trip models.Trip, session *models.Session, at time.Time) (models.TripRule, error) {
+	rule := models.TripRule{Type: models.TripRuleSplit, CreatedAt: time.Now()}
+
+	if session != nil {
+		index := -1
+		for i, s := range trip.Sessions {
+			if s.ID == session.ID {
+				index = i
+			}
+		}
+		switch {
+		case index < 0:
+			return rule, fmt.Errorf("session %d is not part of trip %d", session.ID, trip.ID)
+		case index == 0:
+			return rule, fmt.Errorf("trip %d already starts at session %d", trip.ID, session.ID)
+		case len(session.AssetIDs) == 0:
+			return rule, fmt.Errorf("session %d has no photos", session.ID)
+		}
+		rule.AssetID = session.AssetIDs[0]
+		rule.Description = fmt.Sprintf("Split %q at session %s", trip.Name, session.StartTime.Format("2006-01-02 15:04"))
+		return rule, nil
+	}
+
+	if !at.After(trip.StartTime) || !at.Before(trip.EndTime) {
+		return rule, fmt.Errorf("%s is not within trip %d (%s - %s)", at.Format("2006-01-02 15:04"), trip.ID,
+			trip.StartTime.Format("2006-01-02 15:04"), trip.EndTime.Format("2006-01-02 15:04"))
+	}
+	rule.Time = at
+	rule.Description = fmt.Sprintf("Split %q at %s", trip.Name, at.Format("2006-01-02 15:04"))
+	return rule, nil
+}
+
+// NewAttachRule creates a rule moving a session into a trip
+func NewAttachRule(trip models.Trip, session models.Session) (models.TripRule, error) {
+	if len(trip.AssetIDs) == 0 || len(session.AssetIDs) == 0 {
+		return models.TripRule{}, fmt.Errorf("cannot attach without photos")
+	}
+	return models.TripRule{
+		Type:          models.TripRuleAttach,
+		AssetID:       session.AssetIDs[0],
+		TargetAssetID: trip.AssetIDs[0],
+		Description:   fmt.Sprintf("Attach session %s to %q", session.StartTime.Format("2006-01-02 15:04"), trip.Name),
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// NewDetachRule creates a rule removing a session from whatever trip it falls into
+func NewDetachRule(session models.Session) (models.TripRule, error) {
+	if len(session.AssetIDs) == 0 {
+		return models.TripRule{}, fmt.Errorf("session %d has no photos", session.ID)
+	}
+	return models.TripRule{
+		Type:        models.TripRuleDetach,
+		AssetID:     session.AssetIDs[0],
+		Description: fmt.Sprintf("Detach session %s", session.StartTime.Format("2006-01-02 15:04")),
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// applyTripRules applies trip rules in the order they were created. Rules whose sessions
+// or trips no longer exist are reported and skipped.
+func applyTripRules(groups []tripGroup, sessions []models.Session, rules []models.TripRule) []tripGroup {
+	sessionByAsset := make(map[string]models.Session)
+	for _, session := range sessions {
+		for _, assetID := range session.AssetIDs {
+			sessionByAsset[assetID] = session
+		}
+	}
+
+	// findSession returns the group and position of the session containing an asset, or -1
+	findSession := func(assetID string) (int, int) {
+		session, ok := sessionByAsset[assetID]
+		if !ok {
+			return -1, -1
+		}
+		for g, group := range groups {
+			for i, s := range group.sessions {
+				if sameSession(s, session) {
+					return g, i
+				}
+			}
+		}
+		return -1, -1
+	}
+
+	removeSession := func(g, i int) {
+		group := groups[g]
+		group.sessions = append(append([]models.Session{}, group.sessions[:i]...), group.sessions[i+1:]...)
+		group.manual = true
+		groups[g] = group
+		if len(group.sessions) == 0 {
+			groups = append(groups[:g], groups[g+1:]...)
+		}
+	}
+
+	skip := func(rule models.TripRule, reason string) {
+		fmt.Printf("  ⚠️  Trip rule %d not applied (%s): %s\n", rule.ID, reason, rule.Description)
+	}
+
+	for _, rule := range rules {
+		switch rule.Type {
+		case models.TripRuleMerge:
+			g1, _ := findSession(rule.AssetID)
+			g2, _ := findSession(rule.TargetAssetID)
+			if g1 < 0 || g2 < 0 {
+				skip(rule, "trip not found")
+				continue
+			}
+			if g1 == g2 {
+				continue
+			}
+			merged := append(append([]models.Session{}, groups[g1].sessions...), groups[g2].sessions...)
+			sortSessions(merged)
+			groups[g1] = tripGroup{sessions: merged, reason: "merge rule", manual: true}
+			groups = append(groups[:g2], groups[g2+1:]...)
+
+		case models.TripRuleSplit:
+			if rule.AssetID != "" {
+				g, i := findSession(rule.AssetID)
+				if g < 0 {
+					skip(rule, "session not found in a trip")
+					continue
+				}
+				groups = splitGroup(groups, g, i)
+				continue
+			}
+			for g := 0; g < len(groups); g++ {
+				i := sort.Search(len(groups[g].sessions), func(i int) bool {
+					return !groups[g].sessions[i].StartTime.Before(rule.Time)
+				})
+				groups = splitGroup(groups, g, i)
+			}
+
+		case models.TripRuleAttach:
+			session, ok := sessionByAsset[rule.AssetID]
+			if !ok {
+				skip(rule, "session not found")
+				continue
+			}
+			target, _ := findSession(rule.TargetAssetID)
+			if target < 0 {
+				skip(rule, "trip not found")
+				continue
+			}
+			if g, i := findSession(rule.AssetID); g == target {
+				continue
+			} else if g >= 0 {
+				removeSession(g, i)
+				if target, _ = findSession(rule.TargetAssetID); target < 0 {
+					skip(rule, "trip not found")
+					continue
+				}
+			}
+			attached := append(append([]models.Session{}, groups[target].sessions...), session)
+			sortSessions(attached)
+			groups[target] = tripGroup{sessions: attached, reason: "attach rule", manual: true}
+
+		case models.TripRuleDetach:
+			if g, i := findSession(rule.AssetID); g >= 0 {
+				removeSession(g, i)
+			}
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].sessions[0].StartTime.Before(groups[j].sessions[0].StartTime)
+	})
+	return groups
+}
+
+// splitGroup splits group g so that a new group starts at session i. Nothing changes when
+// i is at either end of the group.
+func splitGroup(groups []tripGroup, g, i int) []tripGroup {
+	sessions := groups[g].sessions
+	if i <= 0 || i >= len(sessions) {
+		return groups
+	}
+	before := tripGroup{sessions: sessions[:i:i], reason: "split rule", manual: true}
+	after := tripGroup{sessions: sessions[i:], reason: groups[g].reason, manual: true}
+
+	result := append([]tripGroup{}, groups[:g]...)
+	result = append(result, before, after)
+	return append(result, groups[g+1:]...)
+}
+
+// sameSession reports whether two sessions are the same, comparing their first asset since
+// sessions always contain at least one asset and every asset is in one session
+func sameSession(a, b models.Session) bool {
+	if a.ID != 0 && b.ID != 0 {
+		return a.ID == b.ID
+	}
+	return len(a.AssetIDs) > 0 && len(b.AssetIDs) > 0 && a.AssetIDs[0] == b.AssetIDs[0]
+}
+
+func sortSessions(sessions []models.Session) {
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartTime.Before(sessions[j].StartTime)
+	})
+}
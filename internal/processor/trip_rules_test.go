@@ -0,0 +1,148 @@
+package processor
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+var rulesStart = time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+// ruleSessions returns sessions 1 to n, a day apart, each with one asset named after it
+func ruleSessions(n int) []models.Session {
+	var sessions []models.Session
+	for id := 1; id <= n; id++ {
+		start := rulesStart.AddDate(0, 0, id)
+		sessions = append(sessions, models.Session{
+			ID:        int64(id),
+			StartTime: start,
+			EndTime:   start.Add(time.Hour),
+			AssetIDs:  []string{ruleAsset(id)},
+			CenterLat: 48.8566,
+			CenterLon: 2.3522,
+		})
+	}
+	return sessions
+}
+
+func ruleAsset(session int) string {
+	return fmt.Sprintf("s%d", session)
+}
+
+// ruleGroups builds trip groups from session IDs
+func ruleGroups(sessions []models.Session, ids ...[]int) []tripGroup {
+	var groups []tripGroup
+	for _, group := range ids {
+		var g tripGroup
+		for _, id := range group {
+			g.sessions = append(g.sessions, sessions[id-1])
+		}
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// groupIDs returns the session IDs of each group
+func groupIDs(groups []tripGroup) [][]int {
+	var ids [][]int
+	for _, group := range groups {
+		var g []int
+		for _, session := range group.sessions {
+			g = append(g, int(session.ID))
+		}
+		ids = append(ids, g)
+	}
+	return ids
+}
+
+func TestApplyTripRules(t *testing.T) {
+	sessions := ruleSessions(6)
+	merge := func(a, b int) models.TripRule {
+		return models.TripRule{Type: models.TripRuleMerge, AssetID: ruleAsset(a), TargetAssetID: ruleAsset(b)}
+	}
+	splitAt := func(s int) models.TripRule {
+		return models.TripRule{Type: models.TripRuleSplit, AssetID: ruleAsset(s)}
+	}
+	splitTime := func(t time.Time) models.TripRule {
+		return models.TripRule{Type: models.TripRuleSplit, Time: t}
+	}
+	attach := func(s, trip int) models.TripRule {
+		return models.TripRule{Type: models.TripRuleAttach, AssetID: ruleAsset(s), TargetAssetID: ruleAsset(trip)}
+	}
+	detach := func(s int) models.TripRule {
+		return models.TripRule{Type: models.TripRuleDetach, AssetID: ruleAsset(s)}
+	}
+
+	tests := []struct {
+		name   string
+		groups [][]int
+		rules  []models.TripRule
+		want   [][]int
+	}{
+		{"merge", [][]int{{1, 2}, {3}, {4}}, []models.TripRule{merge(1, 3)}, [][]int{{1, 2, 3}, {4}}},
+		{"merge in either order", [][]int{{1}, {2, 3}}, []models.TripRule{merge(3, 1)}, [][]int{{1, 2, 3}}},
+		{"merge within a trip", [][]int{{1, 2}}, []models.TripRule{merge(1, 2)}, [][]int{{1, 2}}},
+		{"split at session", [][]int{{1, 2, 3}}, []models.TripRule{splitAt(2)}, [][]int{{1}, {2, 3}}},
+		{"split at first session", [][]int{{1, 2, 3}}, []models.TripRule{splitAt(1)}, [][]int{{1, 2, 3}}},
+		{"split at time", [][]int{{1, 2}, {3, 4, 5}}, []models.TripRule{splitTime(rulesStart.AddDate(0, 0, 4))}, [][]int{{1, 2}, {3}, {4, 5}}},
+		{"split at time outside trips", [][]int{{1, 2}, {4, 5}}, []models.TripRule{splitTime(rulesStart.AddDate(0, 0, 3))}, [][]int{{1, 2}, {4, 5}}},
+		{"attach from another trip", [][]int{{1, 2}, {3, 4}}, []models.TripRule{attach(3, 1)}, [][]int{{1, 2, 3}, {4}}},
+		{"attach the only session of a trip", [][]int{{1}, {2}}, []models.TripRule{attach(2, 1)}, [][]int{{1, 2}}},
+		{"attach a session in no trip", [][]int{{1, 2}}, []models.TripRule{attach(6, 1)}, [][]int{{1, 2, 6}}},
+		{"attach within its trip", [][]int{{1, 2}}, []models.TripRule{attach(2, 1)}, [][]int{{1, 2}}},
+		{"detach", [][]int{{1, 2, 3}}, []models.TripRule{detach(2)}, [][]int{{1, 3}}},
+		{"detach the only session of a trip", [][]int{{1}, {2}}, []models.TripRule{detach(1)}, [][]int{{2}}},
+		{"detach a session in no trip", [][]int{{1}}, []models.TripRule{detach(6)}, [][]int{{1}}},
+		{"rules apply in order", [][]int{{1, 2}, {3, 4}}, []models.TripRule{merge(1, 3), splitAt(4)}, [][]int{{1, 2, 3}, {4}}},
+		{"merge with a missing trip", [][]int{{1}, {2}}, []models.TripRule{merge(1, 6)}, [][]int{{1}, {2}}},
+		{"merge with a deleted session", [][]int{{1}, {2}}, []models.TripRule{merge(1, 99)}, [][]int{{1}, {2}}},
+		{"split at a deleted session", [][]int{{1, 2}}, []models.TripRule{splitAt(99)}, [][]int{{1, 2}}},
+		{"attach a deleted session", [][]int{{1, 2}}, []models.TripRule{attach(99, 1)}, [][]int{{1, 2}}},
+		{"attach to a missing trip", [][]int{{1}, {2}}, []models.TripRule{attach(2, 6)}, [][]int{{1}, {2}}},
+		{"detach a deleted session", [][]int{{1, 2}}, []models.TripRule{detach(99)}, [][]int{{1, 2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := groupIDs(applyTripRules(ruleGroups(sessions, tt.groups...), sessions, tt.rules))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groups = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTripRulesMarksManualGroups(t *testing.T) {
+	sessions := ruleSessions(4)
+	groups := applyTripRules(ruleGroups(sessions, []int{1, 2}, []int{3}, []int{4}), sessions, []models.TripRule{
+		{Type: models.TripRuleDetach, AssetID: ruleAsset(2)},
+	})
+	var manual []bool
+	for _, group := range groups {
+		manual = append(manual, group.manual)
+	}
+	if want := []bool{true, false, false}; !reflect.DeepEqual(manual, want) {
+		t.Errorf("manual = %v, want %v", manual, want)
+	}
+}
+
+func TestDetectTripsKeepsManualTrips(t *testing.T) {
+	sessions := ruleSessions(3)
+	criteria := DefaultTripCriteria()
+	criteria.MinSessions = 2
+	criteria.MinDuration = 100 * 24 * time.Hour
+	criteria.MaxSessionGap = time.Hour // Every session is its own trip
+
+	if trips := DetectTrips(append([]models.Session(nil), sessions...), nil, criteria, nil); len(trips) != 0 {
+		t.Fatalf("got %d trips without rules, want none below MinSessions and MinDuration", len(trips))
+	}
+
+	criteria.Rules = []models.TripRule{{Type: models.TripRuleMerge, AssetID: ruleAsset(1), TargetAssetID: ruleAsset(2)}}
+	trips := DetectTrips(append([]models.Session(nil), sessions...), nil, criteria, nil)
+	if len(trips) != 1 || trips[0].SessionCount != 2 {
+		t.Fatalf("got %+v, want the merged trip of 2 sessions only", trips)
+	}
+}
@@ -0,0 +1,232 @@
+package processor
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+const (
+	summaryMinConfidence = 0.3   // Inferred locations below this are left out of the route
+	flightMinSpeedKmh    = 200.0 // Faster than this between two photos can't be on the ground
+	flightMinDistanceKm  = 100.0 // Shorter jumps are more likely GPS glitches than flights
+)
+
+// routePoint is a located photo on a trip's route
+type routePoint struct {
+	asset        models.Asset
+	lat, lon     float64
+	photographer string
+}
+
+// SummarizeTrips computes the route summary of each trip from the locations of its photos.
+// Private assets, see PrivateAssets, are left out so the summary never names those places.
+func SummarizeTrips(trips []models.Trip, assets []models.Asset, inferences map[string]models.LocationInference, devices map[string]models.Device, homes []models.HomeLocation, private map[string]bool) {
+	assetMap := make(map[string]models.Asset, len(assets))
+	for _, asset := range assets {
+		assetMap[asset.ID] = asset
+	}
+	for i := range trips {
+		trips[i].Summary = SummarizeTrip(trips[i], assetMap, inferences, devices, homes, private)
+	}
+}
+
+// SummarizeTrip computes the countries and cities a trip visited, where it was each day,
+// its flights, how far it got from home and how many nights it lasted. Private assets are
+// left out.
+func SummarizeTrip(trip models.Trip, assetMap map[string]models.Asset, inferences map[string]models.LocationInference, devices map[string]models.Device, homes []models.HomeLocation, private map[string]bool) models.TripSummary {
+	var summary models.TripSummary
+
+	var tripAssets []models.Asset
+	var route []routePoint
+	for _, assetID := range trip.AssetIDs {
+		asset, ok := assetMap[assetID]
+		if !ok || private[assetID] {
+			continue
+		}
+		tripAssets = append(tripAssets, asset)
+		lat, lon, hasLoc, conf := GetEffectiveLocation(asset, inferences)
+		if !hasLoc || conf < summaryMinConfidence {
+			continue
+		}
+		photographer := ""
+		if deviceID := ResolveDevice(asset, devices); deviceID != "" {
+			photographer = devices[deviceID].Photographer
+		}
+		route = append(route, routePoint{asset: asset, lat: lat, lon: lon, photographer: photographer})
+	}
+	sort.SliceStable(route, func(i, j int) bool {
+		a, b := route[i].asset, route[j].asset
+		if !a.LocalDateTime.Equal(b.LocalDateTime) {
+			return a.LocalDateTime.Before(b.LocalDateTime)
+		}
+		return a.ID < b.ID
+	})
+
+	seenCountries := make(map[string]bool)
+	seenCities := make(map[string]bool)
+	for _, point := range route {
+		if c := point.asset.Country; c != "" && !seenCountries[c] {
+			seenCountries[c] = true
+			summary.Countries = append(summary.Countries, c)
+		}
+		if c := point.asset.City; c != "" && !seenCities[c] {
+			seenCities[c] = true
+			summary.Cities = append(summary.Cities, c)
+		}
+	}
+
+	summary.Days = tripDays(trip.StartTime, trip.EndTime, tripAssets, route)
+	summary.Flights = detectFlights(route)
+	summary.FarthestDistance = farthestFromHome(route, homes, SessionPhotographers(models.Session{Photographer: trip.Photographers}))
+	summary.NightsAway = int(math.Round(calendarDay(trip.EndTime).Sub(calendarDay(trip.StartTime)).Hours() / 24))
+
+	return summary
+}
+
+// calendarDay returns midnight of t's date, in UTC so days are always 24 hours
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// tripDays returns one entry per calendar day from start to end, placed in the city with
+// the most photos that day
+func tripDays(start, end time.Time, assets []models.Asset, route []routePoint) []models.TripDay {
+	photos := make(map[string]int)
+	for _, asset := range assets {
+		photos[asset.LocalDateTime.Format("2006-01-02")]++
+	}
+	pointsByDay := make(map[string][]routePoint)
+	for _, point := range route {
+		date := point.asset.LocalDateTime.Format("2006-01-02")
+		pointsByDay[date] = append(pointsByDay[date], point)
+	}
+
+	var days []models.TripDay
+	for day := calendarDay(start); !day.After(calendarDay(end)); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		entry := models.TripDay{Date: date, Photos: photos[date]}
+		points := pointsByDay[date]
+
+		// Most photographed city, on ties the later one where the day ended
+		cityCount := make(map[string]int)
+		for _, point := range points {
+			city := point.asset.City
+			if city == "" {
+				continue
+			}
+			cityCount[city]++
+			if cityCount[city] >= cityCount[entry.City] {
+				entry.City, entry.Country = city, point.asset.Country
+			}
+		}
+
+		var sumLat, sumLon float64
+		count := 0
+		for _, point := range points {
+			if point.asset.City == entry.City {
+				sumLat += point.lat
+				sumLon += point.lon
+				count++
+			}
+		}
+		if count > 0 {
+			entry.Latitude, entry.Longitude = sumLat/float64(count), sumLon/float64(count)
+		}
+		days = append(days, entry)
+	}
+	return days
+}
+
+// detectFlights finds jumps between consecutive photos of the same photographer that are
+// too fast for the ground. A flight photographed by several people is reported once.
+func detectFlights(route []routePoint) []models.FlightLeg {
+	byPhotographer := make(map[string][]routePoint)
+	var photographers []string
+	for _, point := range route {
+		if _, ok := byPhotographer[point.photographer]; !ok {
+			photographers = append(photographers, point.photographer)
+		}
+		byPhotographer[point.photographer] = append(byPhotographer[point.photographer], point)
+	}
+
+	var flights []models.FlightLeg
+	for _, photographer := range photographers {
+		points := byPhotographer[photographer]
+		for i := 1; i < len(points); i++ {
+			from, to := points[i-1], points[i]
+			distance := CalculateDistance(from.lat, from.lon, to.lat, to.lon)
+			if distance < flightMinDistanceKm {
+				continue
+			}
+			hours := to.asset.LocalDateTime.Sub(from.asset.LocalDateTime).Hours()
+			if hours > 0 && distance/hours <= flightMinSpeedKmh {
+				continue
+			}
+			leg := models.FlightLeg{
+				From:       from.asset.City,
+				To:         to.asset.City,
+				Departure:  from.asset.LocalDateTime,
+				Arrival:    to.asset.LocalDateTime,
+				FromLat:    from.lat,
+				FromLon:    from.lon,
+				ToLat:      to.lat,
+				ToLon:      to.lon,
+				DistanceKM: distance,
+			}
+			if !sameFlight(flights, leg) {
+				flights = append(flights, leg)
+			}
+		}
+	}
+
+	sort.SliceStable(flights, func(i, j int) bool {
+		return flights[i].Departure.Before(flights[j].Departure)
+	})
+	return flights
+}
+
+// sameFlight reports whether a leg was already found in another photographer's photos
+func sameFlight(flights []models.FlightLeg, leg models.FlightLeg) bool {
+	for _, f := range flights {
+		overlap := !leg.Departure.After(f.Arrival) && !f.Departure.After(leg.Arrival)
+		if overlap &&
+			CalculateDistance(f.FromLat, f.FromLon, leg.FromLat, leg.FromLon) < flightMinDistanceKm &&
+			CalculateDistance(f.ToLat, f.ToLon, leg.ToLat, leg.ToLon) < flightMinDistanceKm {
+			return true
+		}
+	}
+	return false
+}
+
+// farthestFromHome returns the largest distance from a photo to the nearest home of its
+// photographer. Photos without a photographer are measured against the trip's photographers.
+func farthestFromHome(route []routePoint, homes []models.HomeLocation, tripPhotographers []string) float64 {
+	farthest := 0.0
+	for _, point := range route {
+		photographers := tripPhotographers
+		if point.photographer != "" {
+			photographers = []string{point.photographer}
+		}
+		nearest := -1.0
+		for _, photographer := range photographers {
+			for _, home := range HomesFor(homes, photographer, point.asset.LocalDateTime) {
+				if d := DistanceToHome(home, point.lat, point.lon); nearest < 0 || d < nearest {
+					nearest = d
+				}
+			}
+		}
+		if nearest > farthest {
+			farthest = nearest
+		}
+	}
+	return farthest
+}
+
+// RouteString joins places in order, e.g. "Paris → Lyon → Nice"
+func RouteString(places []string) string {
+	return strings.Join(places, " → ")
+}
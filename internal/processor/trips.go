@@ -11,14 +11,23 @@ import (
 
 // TripCriteria defines parameters for trip detection
 type TripCriteria struct {
-	MinDistanceFromHome float64       // km, sessions closer than this are not trips
-	MaxSessionGap       time.Duration // max time between sessions to group into same trip
-	MinDuration         time.Duration // minimum trip duration
-	MinSessions         int           // minimum sessions to form a trip
-	MaxHomeStayDuration time.Duration // max time at home before trip splits (for brief returns home)
-	ForceSplitDates     []time.Time   // dates where trips should be forcefully split
+	MinDistanceFromHome float64           // km, sessions closer than this are not trips
+	MaxSessionGap       time.Duration     // max time between sessions to group into same trip
+	MinDuration         time.Duration     // minimum trip duration
+	MinSessions         int               // minimum sessions to form a trip
+	MaxHomeStayDuration time.Duration     // max time at home before trip splits (for brief returns home)
+	ForceSplitDates     []time.Time       // dates where trips should be forcefully split
+	Rules               []models.TripRule // manual merges, splits and session moves, applied in order
+	AwayPolicy          string            // AwayIfAny or AwayIfAll, for sessions with several photographers
+	ExclusionZones      []models.Zone     // areas that never count as away from home
 }
 
+// Away policies for sessions with several photographers, whose homes may differ
+const (
+	AwayIfAny = "any" // away when any photographer is away from their homes
+	AwayIfAll = "all" // away only when every photographer is away from their homes
+)
+
 // DefaultTripCriteria returns sensible defaults
 func DefaultTripCriteria() TripCriteria {
 	return TripCriteria{
@@ -27,6 +36,7 @@ func DefaultTripCriteria() TripCriteria {
 		MinDuration:         2 * time.Hour,   // at least 2 hours
 		MinSessions:         1,               // even single session can be a trip
 		MaxHomeStayDuration: 36 * time.Hour,  // if home for more than 1.5 days, trip ends
+		AwayPolicy:          AwayIfAny,       // a trip for anyone is a trip
 	}
 }
 
@@ -56,9 +66,14 @@ func DetectTrips(sessions []models.Session, homes []models.HomeLocation, criteri
 
 	var allSessions []sessionWithHomeStatus
 	awayCount := 0
+	excludedCount := 0
 	for _, session := range sessions {
-		minDistanceFromHome := calculateMinDistanceFromHomes(session, homes)
+		minDistanceFromHome := calculateMinDistanceFromHomes(session, homes, criteria.AwayPolicy)
 		atHome := minDistanceFromHome < criteria.MinDistanceFromHome
+		if !atHome && ZoneAt(criteria.ExclusionZones, session.CenterLat, session.CenterLon) != nil {
+			atHome = true
+			excludedCount++
+		}
 
 		allSessions = append(allSessions, sessionWithHomeStatus{
 			session: session,
@@ -71,6 +86,9 @@ func DetectTrips(sessions []models.Session, homes []models.HomeLocation, criteri
 	}
 
 	fmt.Printf("Sessions away from home (>%.0fkm): %d\n", criteria.MinDistanceFromHome, awayCount)
+	if excludedCount > 0 {
+		fmt.Printf("Sessions in exclusion zones: %d\n", excludedCount)
+	}
 
 	if awayCount == 0 {
 		fmt.Println("No sessions found away from home. Add home locations first!")
@@ -83,7 +101,7 @@ func DetectTrips(sessions []models.Session, homes []models.HomeLocation, criteri
 	// 2. Time gap exceeds MaxSessionGap
 	// 3. We cross a forced split date
 	// 4. We reach the end of sessions
-	var trips []models.Trip
+	var groups []tripGroup
 	var currentTripSessions []models.Session
 	var lastHomeReturnTime *time.Time
 	inTrip := false
@@ -108,13 +126,7 @@ func DetectTrips(sessions []models.Session, homes []models.HomeLocation, criteri
 
 		if shouldForceSplit && inTrip && len(currentTripSessions) > 0 {
 			// Force split - finalize current trip
-			if len(currentTripSessions) >= criteria.MinSessions {
-				trip := createTripFromSessions(currentTripSessions, homes, assetMap)
-				if trip.EndTime.Sub(trip.StartTime) >= criteria.MinDuration {
-					trips = append(trips, trip)
-					fmt.Printf("  Trip ended (forced split): %s\n", trip.Name)
-				}
-			}
+			groups = append(groups, tripGroup{sessions: currentTripSessions, reason: "forced split"})
 			// Start new trip with current session
 			currentTripSessions = []models.Session{s.session}
 			inTrip = true
@@ -143,13 +155,10 @@ func DetectTrips(sessions []models.Session, homes []models.HomeLocation, criteri
 					homeStayDuration := s.session.StartTime.Sub(*lastHomeReturnTime)
 					if homeStayDuration > criteria.MaxHomeStayDuration {
 						// We stayed home too long - this is a new trip
-						if len(currentTripSessions) >= criteria.MinSessions {
-							trip := createTripFromSessions(currentTripSessions, homes, assetMap)
-							if trip.EndTime.Sub(trip.StartTime) >= criteria.MinDuration {
-								trips = append(trips, trip)
-								fmt.Printf("  Trip ended (stayed home %v): %s\n", homeStayDuration.Round(time.Hour), trip.Name)
-							}
-						}
+						groups = append(groups, tripGroup{
+							sessions: currentTripSessions,
+							reason:   fmt.Sprintf("stayed home %v", homeStayDuration.Round(time.Hour)),
+						})
 						// Start new trip
 						currentTripSessions = []models.Session{s.session}
 						lastHomeReturnTime = nil
@@ -168,13 +177,10 @@ func DetectTrips(sessions []models.Session, homes []models.HomeLocation, criteri
 						currentTripSessions = append(currentTripSessions, s.session)
 					} else {
 						// Time gap too large - end current trip and start new one
-						if len(currentTripSessions) >= criteria.MinSessions {
-							trip := createTripFromSessions(currentTripSessions, homes, assetMap)
-							if trip.EndTime.Sub(trip.StartTime) >= criteria.MinDuration {
-								trips = append(trips, trip)
-								fmt.Printf("  Trip ended (time gap %v): %s\n", timeGap.Round(time.Hour), trip.Name)
-							}
-						}
+						groups = append(groups, tripGroup{
+							sessions: currentTripSessions,
+							reason:   fmt.Sprintf("time gap %v", timeGap.Round(time.Hour)),
+						})
 						// Start new trip
 						currentTripSessions = []models.Session{s.session}
 						lastHomeReturnTime = nil
@@ -185,14 +191,26 @@ func DetectTrips(sessions []models.Session, homes []models.HomeLocation, criteri
 
 		// If this is the last session and we're in a trip, finalize it
 		if i == len(allSessions)-1 && inTrip && len(currentTripSessions) > 0 {
-			if len(currentTripSessions) >= criteria.MinSessions {
-				trip := createTripFromSessions(currentTripSessions, homes, assetMap)
-				if trip.EndTime.Sub(trip.StartTime) >= criteria.MinDuration {
-					trips = append(trips, trip)
-					fmt.Printf("  Trip ended (end of sessions): %s\n", trip.Name)
-				}
-			}
+			groups = append(groups, tripGroup{sessions: currentTripSessions, reason: "end of sessions"})
+		}
+	}
+
+	if len(criteria.Rules) > 0 {
+		fmt.Printf("Applying %d trip rules\n", len(criteria.Rules))
+		groups = applyTripRules(groups, sessions, criteria.Rules)
+	}
+
+	var trips []models.Trip
+	for _, group := range groups {
+		if !group.manual && len(group.sessions) < criteria.MinSessions {
+			continue
+		}
+		trip := createTripFromSessions(group.sessions, homes, criteria.AwayPolicy, assetMap)
+		if !group.manual && trip.EndTime.Sub(trip.StartTime) < criteria.MinDuration {
+			continue
 		}
+		trips = append(trips, trip)
+		fmt.Printf("  Trip ended (%s): %s\n", group.reason, trip.Name)
 	}
 
 	fmt.Printf("Detected %d trips\n", len(trips))
@@ -200,17 +218,28 @@ func DetectTrips(sessions []models.Session, homes []models.HomeLocation, criteri
 	return trips
 }
 
-func calculateMinDistanceFromHomes(session models.Session, homes []models.HomeLocation) float64 {
+// calculateMinDistanceFromHomes returns the distance in km from a session to the nearest home
+// of its photographer that was lived in when the session started. For sessions with several
+// photographers the away policy picks the farthest (any) or nearest (all) of their distances.
+func calculateMinDistanceFromHomes(session models.Session, homes []models.HomeLocation, policy string) float64 {
+	distance := -1.0
+	for _, photographer := range SessionPhotographers(session) {
+		d := minDistanceToHomes(session, HomesFor(homes, photographer, session.StartTime))
+		if distance < 0 || (policy == AwayIfAll && d < distance) || (policy != AwayIfAll && d > distance) {
+			distance = d
+		}
+	}
+	return distance
+}
+
+func minDistanceToHomes(session models.Session, homes []models.HomeLocation) float64 {
 	if len(homes) == 0 {
 		return 999999.0 // Very far if no homes defined
 	}
 
 	minDistance := 999999.0
 	for _, home := range homes {
-		distance := CalculateDistance(
-			session.CenterLat, session.CenterLon,
-			home.Latitude, home.Longitude,
-		)
+		distance := DistanceToHome(home, session.CenterLat, session.CenterLon)
 		if distance < minDistance {
 			minDistance = distance
 		}
@@ -219,7 +248,7 @@ func calculateMinDistanceFromHomes(session models.Session, homes []models.HomeLo
 	return minDistance
 }
 
-func createTripFromSessions(sessions []models.Session, homes []models.HomeLocation, assetMap map[string]models.Asset) models.Trip {
+func createTripFromSessions(sessions []models.Session, homes []models.HomeLocation, policy string, assetMap map[string]models.Asset) models.Trip {
 	// Calculate trip bounds
 	startTime := sessions[0].StartTime
 	endTime := sessions[len(sessions)-1].EndTime
@@ -245,7 +274,7 @@ func createTripFromSessions(sessions []models.Session, homes []models.HomeLocati
 	centerLon := sumLon / float64(len(sessions))
 
 	// Calculate distance from home
-	minHomeDistance := calculateMinDistanceFromHomes(sessions[0], homes)
+	minHomeDistance := calculateMinDistanceFromHomes(sessions[0], homes, policy)
 
 	// Calculate total travel distance (sum of distances between session centers)
 	totalDistance := 0.0
@@ -257,9 +286,6 @@ func createTripFromSessions(sessions []models.Session, homes []models.HomeLocati
 		totalDistance += dist
 	}
 
-	// Generate trip name
-	name := generateTripName(sessions, startTime, endTime, centerLat, centerLon, assetMap)
-
 	// Collect photographers
 	var photographers []string
 	for p := range photographerSet {
@@ -267,8 +293,7 @@ func createTripFromSessions(sessions []models.Session, homes []models.HomeLocati
 	}
 	sort.Strings(photographers)
 
-	return models.Trip{
-		Name:          name,
+	trip := models.Trip{
 		StartTime:     startTime,
 		EndTime:       endTime,
 		Sessions:      sessions,
@@ -280,38 +305,10 @@ func createTripFromSessions(sessions []models.Session, homes []models.HomeLocati
 		Photographers: strings.Join(photographers, ", "),
 		SessionCount:  len(sessions),
 	}
-}
 
-func generateTripName(sessions []models.Session, start, end time.Time, lat, lon float64, assetMap map[string]models.Asset) string {
-	// Try to extract location from session data
-	location := extractLocationFromSessions(sessions, assetMap)
-
-	// Format dates
-	if start.Year() == end.Year() && start.Month() == end.Month() && start.Day() == end.Day() {
-		// Single day trip
-		dateStr := start.Format("Jan 2, 2006")
-		if location != "" {
-			return fmt.Sprintf("%s - %s", location, dateStr)
-		}
-		return fmt.Sprintf("Trip - %s", dateStr)
-	} else {
-		// Multi-day trip
-		if start.Year() == end.Year() && start.Month() == end.Month() {
-			// Same month
-			dateStr := fmt.Sprintf("%s %d-%d, %d", start.Format("Jan"), start.Day(), end.Day(), start.Year())
-			if location != "" {
-				return fmt.Sprintf("%s - %s", location, dateStr)
-			}
-			return fmt.Sprintf("Trip - %s", dateStr)
-		} else {
-			// Different months
-			dateStr := fmt.Sprintf("%s - %s", start.Format("Jan 2"), end.Format("Jan 2, 2006"))
-			if location != "" {
-				return fmt.Sprintf("%s - %s", location, dateStr)
-			}
-			return fmt.Sprintf("Trip - %s", dateStr)
-		}
-	}
+	// Generate trip name
+	trip.Name, _ = defaultTripNamer.Name(trip, assetMap)
+	return trip
 }
 
 func extractLocationFromSessions(sessions []models.Session, assetMap map[string]models.Asset) string {
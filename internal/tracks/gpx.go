@@ -0,0 +1,93 @@
+package tracks
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+type gpxFile struct {
+	Tracks []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+	Waypoints []gpxPoint `xml:"wpt"`
+}
+
+type gpxPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Time string  `xml:"time"`
+}
+
+// parseGPX reads timestamped track points and waypoints from a GPX file
+func parseGPX(r io.Reader, loc *time.Location) ([]models.TrackPoint, error) {
+	var gpx gpxFile
+	if err := xml.NewDecoder(r).Decode(&gpx); err != nil {
+		return nil, fmt.Errorf("failed to decode GPX: %w", err)
+	}
+
+	var raw []gpxPoint
+	for _, track := range gpx.Tracks {
+		for _, segment := range track.Segments {
+			raw = append(raw, segment.Points...)
+		}
+	}
+	raw = append(raw, gpx.Waypoints...)
+
+	var points []models.TrackPoint
+	for _, p := range raw {
+		if p.Time == "" || !validCoordinate(p.Lat, p.Lon) {
+			continue
+		}
+		t, err := parseTime(p.Time, loc)
+		if err != nil {
+			continue
+		}
+		points = append(points, models.TrackPoint{Time: t, Latitude: p.Lat, Longitude: p.Lon})
+	}
+
+	return points, nil
+}
+
+type gpxOutput struct {
+	XMLName xml.Name `xml:"gpx"`
+	Version string   `xml:"version,attr"`
+	Creator string   `xml:"creator,attr"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Track   struct {
+		Name    string `xml:"name"`
+		Segment struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+// WriteGPX writes points as a single GPX track. Times are local wall-clock times without a
+// UTC offset, like the photo times they come from.
+func WriteGPX(w io.Writer, name string, points []models.TrackPoint) error {
+	out := gpxOutput{Version: "1.1", Creator: "immich-albums", Xmlns: "http://www.topografix.com/GPX/1/1"}
+	out.Track.Name = name
+	for _, p := range points {
+		out.Track.Segment.Points = append(out.Track.Segment.Points, gpxPoint{
+			Lat:  p.Latitude,
+			Lon:  p.Longitude,
+			Time: p.Time.Format("2006-01-02T15:04:05"),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,103 @@
+package tracks
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// parseKML reads gx:Track elements (Google Location History, most trackers) and
+// placemarks with a TimeStamp and a Point
+func parseKML(r io.Reader, loc *time.Location) ([]models.TrackPoint, error) {
+	decoder := xml.NewDecoder(r)
+
+	var points []models.TrackPoint
+	var path []string
+	var whens, coords []string
+	var placemarkWhen, placemarkCoords string
+	inTrack := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode KML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			switch t.Name.Local {
+			case "Track":
+				inTrack = true
+				whens, coords = nil, nil
+			case "Placemark":
+				placemarkWhen, placemarkCoords = "", ""
+			}
+		case xml.CharData:
+			if len(path) == 0 {
+				continue
+			}
+			text := strings.TrimSpace(string(t))
+			if text == "" {
+				continue
+			}
+			switch element := path[len(path)-1]; {
+			case inTrack && element == "when":
+				whens = append(whens, text)
+			case inTrack && element == "coord":
+				coords = append(coords, text)
+			case element == "when" && len(path) > 1 && path[len(path)-2] == "TimeStamp":
+				placemarkWhen = text
+			case element == "coordinates" && len(path) > 1 && path[len(path)-2] == "Point":
+				placemarkCoords = text
+			}
+		case xml.EndElement:
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+			switch t.Name.Local {
+			case "Track":
+				inTrack = false
+				// gx:Track lists all times, then all coordinates, in matching order
+				for i := 0; i < len(whens) && i < len(coords); i++ {
+					if p, ok := kmlPoint(whens[i], strings.Fields(coords[i]), loc); ok {
+						points = append(points, p)
+					}
+				}
+			case "Placemark":
+				if placemarkWhen != "" && placemarkCoords != "" {
+					if p, ok := kmlPoint(placemarkWhen, strings.Split(placemarkCoords, ","), loc); ok {
+						points = append(points, p)
+					}
+				}
+			}
+		}
+	}
+
+	return points, nil
+}
+
+// kmlPoint builds a track point from a timestamp and "lon lat [alt]" fields
+func kmlPoint(when string, fields []string, loc *time.Location) (models.TrackPoint, bool) {
+	if len(fields) < 2 {
+		return models.TrackPoint{}, false
+	}
+	lon, err1 := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+	lat, err2 := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+	if err1 != nil || err2 != nil || !validCoordinate(lat, lon) {
+		return models.TrackPoint{}, false
+	}
+	t, err := parseTime(when, loc)
+	if err != nil {
+		return models.TrackPoint{}, false
+	}
+	return models.TrackPoint{Time: t, Latitude: lat, Longitude: lon}, true
+}
@@ -0,0 +1,236 @@
+package tracks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// timelineFile covers the Google Location History formats: Takeout Records.json
+// (locations), Takeout Semantic Location History (timelineObjects) and the on-device
+// Android export (semanticSegments, rawSignals). The iOS export is a bare array of segments.
+type timelineFile struct {
+	Locations        []timelineRecord    `json:"locations"`
+	TimelineObjects  []timelineObject    `json:"timelineObjects"`
+	SemanticSegments []timelineSegment   `json:"semanticSegments"`
+	RawSignals       []timelineRawSignal `json:"rawSignals"`
+}
+
+type timelineRecord struct {
+	LatitudeE7  *int64 `json:"latitudeE7"`
+	LongitudeE7 *int64 `json:"longitudeE7"`
+	Timestamp   string `json:"timestamp"`
+	TimestampMs string `json:"timestampMs"`
+}
+
+type timelineE7 struct {
+	LatitudeE7  *int64 `json:"latitudeE7"`
+	LongitudeE7 *int64 `json:"longitudeE7"`
+	LatE7       *int64 `json:"latE7"`
+	LngE7       *int64 `json:"lngE7"`
+	Timestamp   string `json:"timestamp"`
+	TimestampMs string `json:"timestampMs"`
+}
+
+type timelineDuration struct {
+	StartTimestamp   string `json:"startTimestamp"`
+	EndTimestamp     string `json:"endTimestamp"`
+	StartTimestampMs string `json:"startTimestampMs"`
+	EndTimestampMs   string `json:"endTimestampMs"`
+}
+
+type timelineObject struct {
+	PlaceVisit *struct {
+		Location timelineE7       `json:"location"`
+		Duration timelineDuration `json:"duration"`
+	} `json:"placeVisit"`
+	ActivitySegment *struct {
+		StartLocation     timelineE7       `json:"startLocation"`
+		EndLocation       timelineE7       `json:"endLocation"`
+		Duration          timelineDuration `json:"duration"`
+		SimplifiedRawPath *struct {
+			Points []timelineE7 `json:"points"`
+		} `json:"simplifiedRawPath"`
+	} `json:"activitySegment"`
+}
+
+type timelineSegment struct {
+	StartTime    string `json:"startTime"`
+	EndTime      string `json:"endTime"`
+	TimelinePath []struct {
+		Point         string `json:"point"`
+		Time          string `json:"time"`
+		OffsetMinutes string `json:"durationMinutesOffsetFromStartTime"`
+	} `json:"timelinePath"`
+	Visit *struct {
+		TopCandidate struct {
+			PlaceLocation json.RawMessage `json:"placeLocation"`
+		} `json:"topCandidate"`
+	} `json:"visit"`
+	Activity *struct {
+		Start json.RawMessage `json:"start"`
+		End   json.RawMessage `json:"end"`
+	} `json:"activity"`
+}
+
+type timelineRawSignal struct {
+	Position *struct {
+		LatLng    string `json:"LatLng"`
+		Timestamp string `json:"timestamp"`
+	} `json:"position"`
+}
+
+// parseTimeline reads any of the Google Timeline JSON exports. Visits contribute their
+// start and end, activities their start, end and recorded path.
+func parseTimeline(data []byte, loc *time.Location) ([]models.TrackPoint, error) {
+	var file timelineFile
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &file.SemanticSegments); err != nil {
+			return nil, fmt.Errorf("failed to decode timeline: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode timeline: %w", err)
+	}
+
+	var points []models.TrackPoint
+	add := func(t time.Time, lat, lon float64, ok bool) {
+		if ok && !t.IsZero() && validCoordinate(lat, lon) {
+			points = append(points, models.TrackPoint{Time: t, Latitude: lat, Longitude: lon})
+		}
+	}
+
+	for _, record := range file.Locations {
+		if record.LatitudeE7 == nil || record.LongitudeE7 == nil {
+			continue
+		}
+		t := timelineTime(record.Timestamp, record.TimestampMs, loc)
+		add(t, e7(*record.LatitudeE7), e7(*record.LongitudeE7), true)
+	}
+
+	for _, object := range file.TimelineObjects {
+		if visit := object.PlaceVisit; visit != nil {
+			lat, lon, ok := visit.Location.coordinates()
+			add(timelineTime(visit.Duration.StartTimestamp, visit.Duration.StartTimestampMs, loc), lat, lon, ok)
+			add(timelineTime(visit.Duration.EndTimestamp, visit.Duration.EndTimestampMs, loc), lat, lon, ok)
+		}
+		if activity := object.ActivitySegment; activity != nil {
+			lat, lon, ok := activity.StartLocation.coordinates()
+			add(timelineTime(activity.Duration.StartTimestamp, activity.Duration.StartTimestampMs, loc), lat, lon, ok)
+			lat, lon, ok = activity.EndLocation.coordinates()
+			add(timelineTime(activity.Duration.EndTimestamp, activity.Duration.EndTimestampMs, loc), lat, lon, ok)
+			if activity.SimplifiedRawPath != nil {
+				for _, p := range activity.SimplifiedRawPath.Points {
+					lat, lon, ok := p.coordinates()
+					add(timelineTime(p.Timestamp, p.TimestampMs, loc), lat, lon, ok)
+				}
+			}
+		}
+	}
+
+	for _, segment := range file.SemanticSegments {
+		start := timelineTime(segment.StartTime, "", loc)
+		end := timelineTime(segment.EndTime, "", loc)
+
+		for _, p := range segment.TimelinePath {
+			lat, lon, ok := parseLatLng(p.Point)
+			t := timelineTime(p.Time, "", loc)
+			if t.IsZero() && p.OffsetMinutes != "" && !start.IsZero() {
+				if minutes, err := strconv.ParseFloat(p.OffsetMinutes, 64); err == nil {
+					t = start.Add(time.Duration(minutes * float64(time.Minute)))
+				}
+			}
+			add(t, lat, lon, ok)
+		}
+		if segment.Visit != nil {
+			lat, lon, ok := rawLatLng(segment.Visit.TopCandidate.PlaceLocation)
+			add(start, lat, lon, ok)
+			add(end, lat, lon, ok)
+		}
+		if segment.Activity != nil {
+			lat, lon, ok := rawLatLng(segment.Activity.Start)
+			add(start, lat, lon, ok)
+			lat, lon, ok = rawLatLng(segment.Activity.End)
+			add(end, lat, lon, ok)
+		}
+	}
+
+	for _, signal := range file.RawSignals {
+		if signal.Position == nil {
+			continue
+		}
+		lat, lon, ok := parseLatLng(signal.Position.LatLng)
+		add(timelineTime(signal.Position.Timestamp, "", loc), lat, lon, ok)
+	}
+
+	return points, nil
+}
+
+// coordinates returns the location of an E7-encoded point, in either naming scheme
+func (p timelineE7) coordinates() (float64, float64, bool) {
+	switch {
+	case p.LatitudeE7 != nil && p.LongitudeE7 != nil:
+		return e7(*p.LatitudeE7), e7(*p.LongitudeE7), true
+	case p.LatE7 != nil && p.LngE7 != nil:
+		return e7(*p.LatE7), e7(*p.LngE7), true
+	}
+	return 0, 0, false
+}
+
+func e7(v int64) float64 {
+	return float64(v) / 1e7
+}
+
+// timelineTime parses an RFC 3339 timestamp, falling back to epoch milliseconds.
+// Returns the zero time if neither can be parsed.
+func timelineTime(timestamp, timestampMs string, loc *time.Location) time.Time {
+	if timestamp != "" {
+		if t, err := parseTime(timestamp, loc); err == nil {
+			return t
+		}
+	}
+	if timestampMs != "" {
+		if ms, err := strconv.ParseInt(timestampMs, 10, 64); err == nil {
+			return wallClock(time.UnixMilli(ms).In(loc))
+		}
+	}
+	return time.Time{}
+}
+
+// rawLatLng reads a location that is either a string or an object with a latLng field
+func rawLatLng(raw json.RawMessage) (float64, float64, bool) {
+	if len(raw) == 0 {
+		return 0, 0, false
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return parseLatLng(s)
+	}
+	var obj struct {
+		LatLng string `json:"latLng"`
+	}
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		return parseLatLng(obj.LatLng)
+	}
+	return 0, 0, false
+}
+
+// parseLatLng parses "48.1°, 11.5°" (Android) and "geo:48.1,11.5" (iOS)
+func parseLatLng(s string) (float64, float64, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "geo:")
+	s = strings.ReplaceAll(s, "°", "")
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
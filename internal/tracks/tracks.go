@@ -0,0 +1,77 @@
+// Package tracks parses location tracks from GPX, KML and Google Timeline exports.
+package tracks
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jamo/immich-albums/internal/models"
+)
+
+// ParseFile reads a track file and returns its points sorted by time. The format is
+// chosen by extension (.gpx, .kml, .json). Times without a UTC offset of their own are
+// converted to wall-clock time in loc, matching how photo times are compared.
+func ParseFile(path string, loc *time.Location) ([]models.TrackPoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []models.TrackPoint
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gpx":
+		points, err = parseGPX(bytes.NewReader(data), loc)
+	case ".kml":
+		points, err = parseKML(bytes.NewReader(data), loc)
+	case ".json":
+		points, err = parseTimeline(data, loc)
+	default:
+		return nil, fmt.Errorf("unsupported track format %q (expected .gpx, .kml or .json)", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	source := filepath.Base(path)
+	for i := range points {
+		points[i].Source = source
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Time.Before(points[j].Time)
+	})
+
+	return points, nil
+}
+
+// parseTime parses an RFC 3339 timestamp into local wall-clock time. Timestamps with an
+// explicit offset keep their own wall clock; UTC timestamps are converted to loc.
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if strings.HasSuffix(s, "Z") || strings.HasSuffix(s, "z") {
+		t = t.In(loc)
+	}
+	return wallClock(t), nil
+}
+
+// wallClock returns t's wall-clock reading labeled as UTC, the same convention Immich
+// uses for an asset's local date time
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// validCoordinate rejects out-of-range and null-island coordinates
+func validCoordinate(lat, lon float64) bool {
+	if lat == 0 && lon == 0 {
+		return false
+	}
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
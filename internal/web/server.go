@@ -4,11 +4,14 @@ import (
 	"embed"
 	"encoding/json"
 	"html/template"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/jamo/immich-albums/internal/database"
+	"github.com/jamo/immich-albums/internal/geojson"
 	"github.com/jamo/immich-albums/internal/models"
 	"github.com/jamo/immich-albums/internal/processor"
 )
@@ -54,12 +57,27 @@ func NewServer(db *database.DB, immichURL, immichAPIKey string) *Server {
 	s.mux.HandleFunc("/api/heatmap-data", s.handleAPIHeatmapData)
 	s.mux.HandleFunc("/api/homes", s.handleAPIHomes)
 	s.mux.HandleFunc("/api/homes/add", s.handleAPIAddHome)
+	s.mux.HandleFunc("/api/homes/update", s.handleAPIUpdateHome)
+	s.mux.HandleFunc("/api/homes/suggestions", s.handleAPIHomeSuggestions)
 	s.mux.HandleFunc("/api/homes/delete", s.handleAPIDeleteHome)
+	s.mux.HandleFunc("/api/zones", s.handleAPIZones)
+	s.mux.HandleFunc("/api/zones/add", s.handleAPIAddZone)
+	s.mux.HandleFunc("/api/zones/import", s.handleAPIImportZones)
+	s.mux.HandleFunc("/api/zones/delete", s.handleAPIDeleteZone)
 	s.mux.HandleFunc("/api/trips", s.handleAPITrips)
 	s.mux.HandleFunc("/api/trips/update", s.handleAPIUpdateTrip)
 	s.mux.HandleFunc("/api/trips/exclude", s.handleAPIExcludeTrip)
+	s.mux.HandleFunc("/api/trips/merge", s.handleAPIMergeTrips)
+	s.mux.HandleFunc("/api/trips/split", s.handleAPISplitTrip)
+	s.mux.HandleFunc("/api/trips/attach", s.handleAPIAttachSession)
+	s.mux.HandleFunc("/api/trips/detach", s.handleAPIDetachSession)
+	s.mux.HandleFunc("/api/trip-rules", s.handleAPITripRules)
+	s.mux.HandleFunc("/api/trip-rules/delete", s.handleAPIDeleteTripRule)
 	s.mux.HandleFunc("/api/devices", s.handleAPIDevices)
 	s.mux.HandleFunc("/api/devices/label", s.handleAPILabelDevice)
+	s.mux.HandleFunc("/api/clock-offsets/accept", s.handleAPIAcceptClockOffset)
+	s.mux.HandleFunc("/api/clock-offsets/set", s.handleAPISetClockOffset)
+	s.mux.HandleFunc("/api/clock-offsets/delete", s.handleAPIDeleteClockOffset)
 	s.mux.HandleFunc("/api/immich-proxy/", s.handleImmichProxy)
 
 	return s
@@ -101,14 +119,21 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Count assets with GPS
+	inferences, err := s.db.GetInferredLocations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Count assets with GPS and with an inferred location
 	assetsWithGPS := 0
 	assetsWithInferred := 0
 	for _, asset := range assets {
 		if asset.Latitude != nil {
 			assetsWithGPS++
+		} else if _, ok := inferences[asset.ID]; ok {
+			assetsWithInferred++
 		}
-		// TODO: check inferred locations
 	}
 
 	data := struct {
@@ -246,6 +271,14 @@ func (s *Server) handleAPIAddHome(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if len(home.Area) > 0 {
+		if err := geojson.Validate(home.Area); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		outline := processor.AreaHome(home.Name, home.Area)
+		home.Latitude, home.Longitude, home.Radius = outline.Latitude, outline.Longitude, outline.Radius
+	}
 
 	if err := s.db.StoreHomeLocation(home); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -256,6 +289,54 @@ func (s *Server) handleAPIAddHome(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
+func (s *Server) handleAPIUpdateHome(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var home models.HomeLocation
+	if err := json.NewDecoder(r.Body).Decode(&home); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(home.Area) > 0 {
+		if err := geojson.Validate(home.Area); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		outline := processor.AreaHome(home.Name, home.Area)
+		home.Latitude, home.Longitude, home.Radius = outline.Latitude, outline.Longitude, outline.Radius
+	}
+
+	if err := s.db.UpdateHomeLocation(home); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleAPIHomeSuggestions(w http.ResponseWriter, r *http.Request) {
+	assets, err := s.db.GetAssets()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	homes, err := s.db.GetHomeLocations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	suggestions := processor.SuggestPlaces(assets, homes, processor.DefaultPlaceSuggestionParams())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestions)
+}
+
 func (s *Server) handleAPIDeleteHome(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -278,51 +359,114 @@ func (s *Server) handleAPIDeleteHome(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
-func (s *Server) handleAPITrips(w http.ResponseWriter, r *http.Request) {
-	trips, err := s.db.GetTrips()
+func (s *Server) handleAPIZones(w http.ResponseWriter, r *http.Request) {
+	zones, err := s.db.GetZones(r.URL.Query().Get("kind"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Load all sessions to enrich trip data
-	sessions, err := s.db.GetSessions()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(zones)
+}
+
+func (s *Server) handleAPIAddZone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var zone models.Zone
+	if err := json.NewDecoder(r.Body).Decode(&zone); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(zone.Area) > 0 || zone.Radius <= 0 {
+		if err := geojson.Validate(zone.Area); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if zone.Kind == "" {
+		zone.Kind = models.ZoneExclude
+	}
+	zone.CreatedAt = time.Now()
+
+	id, err := s.db.StoreZone(zone)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Create session map for quick lookup
-	sessionMap := make(map[int64]models.Session)
-	for _, session := range sessions {
-		sessionMap[session.ID] = session
-	}
-
-	// Enrich trips with session details
-	for i := range trips {
-		// Find sessions that belong to this trip by checking if their time falls within trip bounds
-		var tripSessions []models.Session
-		for _, session := range sessions {
-			if !session.StartTime.Before(trips[i].StartTime) && !session.EndTime.After(trips[i].EndTime) {
-				// Check if any asset IDs match
-				sessionMatches := false
-				for _, assetID := range session.AssetIDs {
-					for _, tripAssetID := range trips[i].AssetIDs {
-						if assetID == tripAssetID {
-							sessionMatches = true
-							break
-						}
-					}
-					if sessionMatches {
-						break
-					}
-				}
-				if sessionMatches {
-					tripSessions = append(tripSessions, session)
-				}
-			}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "id": id})
+}
+
+// handleAPIImportZones imports the polygons of a GeoJSON body as zones, or as home
+// locations with ?as=home
+func (s *Server) handleAPIImportZones(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	shapes, err := geojson.Parse(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	as := r.URL.Query().Get("as")
+	if as == "" {
+		as = models.ZoneExclude
+	}
+	for _, shape := range shapes {
+		if as == "home" {
+			err = s.db.StoreHomeLocation(processor.AreaHome(shape.Name, shape.Area))
+		} else {
+			_, err = s.db.StoreZone(models.Zone{Name: shape.Name, Kind: as, Area: shape.Area, CreatedAt: time.Now()})
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
-		trips[i].Sessions = tripSessions
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "imported": len(shapes)})
+}
+
+func (s *Server) handleAPIDeleteZone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.DeleteZone(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleAPITrips(w http.ResponseWriter, r *http.Request) {
+	trips, err := s.db.GetTrips()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -364,51 +508,11 @@ func (s *Server) handleCoverage(w http.ResponseWriter, r *http.Request) {
 	// Build set of session IDs that are in trips
 	sessionIDsInTrips := make(map[int64]bool)
 	for _, trip := range trips {
-		// Find sessions that belong to this trip by matching asset IDs
-		for _, session := range sessions {
-			if !session.StartTime.Before(trip.StartTime) && !session.EndTime.After(trip.EndTime) {
-				// Check if any asset IDs match
-				for _, assetID := range session.AssetIDs {
-					for _, tripAssetID := range trip.AssetIDs {
-						if assetID == tripAssetID {
-							sessionIDsInTrips[session.ID] = true
-							break
-						}
-					}
-					if sessionIDsInTrips[session.ID] {
-						break
-					}
-				}
-			}
+		for _, session := range trip.Sessions {
+			sessionIDsInTrips[session.ID] = true
 		}
 	}
 
-	// Enrich trips with their sessions for visualization
-	for i := range trips {
-		var tripSessions []models.Session
-		for _, session := range sessions {
-			if sessionIDsInTrips[session.ID] {
-				// Check if this session belongs to this trip
-				if !session.StartTime.Before(trips[i].StartTime) && !session.EndTime.After(trips[i].EndTime) {
-					for _, assetID := range session.AssetIDs {
-						matches := false
-						for _, tripAssetID := range trips[i].AssetIDs {
-							if assetID == tripAssetID {
-								matches = true
-								break
-							}
-						}
-						if matches {
-							tripSessions = append(tripSessions, session)
-							break
-						}
-					}
-				}
-			}
-		}
-		trips[i].Sessions = tripSessions
-	}
-
 	// Categorize sessions and count statistics
 	var tripsCount, homeCount, orphanCount, otherCount int
 	var photosInTrips, photosAtHome, orphanPhotos int
@@ -422,19 +526,7 @@ func (s *Server) handleCoverage(w http.ResponseWriter, r *http.Request) {
 
 	for _, session := range sessions {
 		// Check if session is at home
-		atHome := false
-		if len(homes) > 0 {
-			for _, home := range homes {
-				distance := processor.CalculateDistance(
-					session.CenterLat, session.CenterLon,
-					home.Latitude, home.Longitude,
-				)
-				if distance <= home.Radius {
-					atHome = true
-					break
-				}
-			}
-		}
+		atHome := processor.SessionAtHome(session, homes)
 
 		enrichedSessions = append(enrichedSessions, EnrichedSession{
 			Session: session,
@@ -551,8 +643,9 @@ func (s *Server) handleAPIUpdateTrip(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update only the name
+	// Update only the name, keeping it when trips are re-detected
 	trip.Name = updateData.Name
+	trip.CustomName = true
 
 	// Save the updated trip
 	if err := s.db.UpdateTrip(trip); err != nil {
@@ -642,22 +735,39 @@ func (s *Server) handleAPIDevices(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	deviceMap := make(map[string]models.Device)
+	for _, device := range devices {
+		deviceMap[device.ID] = device
+	}
+
 	// Group assets by device
 	deviceAssets := make(map[string][]models.Asset)
 	for _, asset := range assets {
 		if asset.Make == "" && asset.Model == "" {
 			continue
 		}
-		deviceID := processor.FindMatchingDevice(asset, devices)
+		deviceID := processor.ResolveDevice(asset, deviceMap)
 		if deviceID != "" {
 			deviceAssets[deviceID] = append(deviceAssets[deviceID], asset)
 		}
 	}
 
+	// Group clock offsets by device
+	offsets, err := s.db.GetClockOffsets()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	deviceOffsets := make(map[string][]models.ClockOffset)
+	for _, offset := range offsets {
+		deviceOffsets[offset.DeviceID] = append(deviceOffsets[offset.DeviceID], offset)
+	}
+
 	// Build response with sample photos (up to 5 per device)
 	type DeviceWithSamples struct {
 		models.Device
-		SampleAssets []models.Asset `json:"sample_assets"`
+		SampleAssets []models.Asset       `json:"sample_assets"`
+		ClockOffsets []models.ClockOffset `json:"clock_offsets"`
 	}
 
 	var response []DeviceWithSamples
@@ -669,6 +779,7 @@ func (s *Server) handleAPIDevices(w http.ResponseWriter, r *http.Request) {
 		response = append(response, DeviceWithSamples{
 			Device:       device,
 			SampleAssets: samples,
+			ClockOffsets: deviceOffsets[device.ID],
 		})
 	}
 
@@ -703,6 +814,303 @@ func (s *Server) handleAPILabelDevice(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
+// handleAPIAcceptClockOffset accepts a suggested clock offset
+func (s *Server) handleAPIAcceptClockOffset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.UpdateClockOffsetStatus(id, models.ClockOffsetAccepted); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleAPISetClockOffset stores a manual clock offset for a device
+func (s *Server) handleAPISetClockOffset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		DeviceID  string `json:"device_id"`
+		Offset    string `json:"offset"`     // Signed duration, e.g. "+1h30m"
+		ValidFrom string `json:"valid_from"` // Optional, YYYY-MM-DD
+		ValidTo   string `json:"valid_to"`   // Optional, YYYY-MM-DD, inclusive
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	offset, err := time.ParseDuration(request.Offset)
+	if err != nil {
+		http.Error(w, "Invalid offset (expected e.g. +1h30m or -7h)", http.StatusBadRequest)
+		return
+	}
+
+	clock := models.ClockOffset{
+		DeviceID:      request.DeviceID,
+		OffsetSeconds: int64(offset / time.Second),
+		Confidence:    1.0,
+		Status:        models.ClockOffsetManual,
+	}
+	if request.ValidFrom != "" {
+		if clock.ValidFrom, err = time.Parse("2006-01-02", request.ValidFrom); err != nil {
+			http.Error(w, "Invalid valid_from date", http.StatusBadRequest)
+			return
+		}
+	}
+	if request.ValidTo != "" {
+		validTo, err := time.Parse("2006-01-02", request.ValidTo)
+		if err != nil {
+			http.Error(w, "Invalid valid_to date", http.StatusBadRequest)
+			return
+		}
+		clock.ValidTo = validTo.Add(24*time.Hour - time.Second)
+	}
+
+	if _, err := s.db.StoreClockOffset(clock); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleAPIDeleteClockOffset removes a clock offset
+func (s *Server) handleAPIDeleteClockOffset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.DeleteClockOffset(id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleAPITripRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := s.db.GetTripRules()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+func (s *Server) handleAPIMergeTrips(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		TripIDs []int64 `json:"trip_ids"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(request.TripIDs) != 2 {
+		http.Error(w, "Expected two trip IDs", http.StatusBadRequest)
+		return
+	}
+
+	a, err := s.db.GetTrip(request.TripIDs[0])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	b, err := s.db.GetTrip(request.TripIDs[1])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	rule, err := processor.NewMergeRule(*a, *b)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.storeTripRule(w, rule)
+}
+
+func (s *Server) handleAPISplitTrip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		TripID    int64  `json:"trip_id"`
+		SessionID int64  `json:"session_id"` // Session that starts the new trip
+		Time      string `json:"time"`       // Or the time that starts it, YYYY-MM-DDTHH:MM or YYYY-MM-DD
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if (request.SessionID == 0) == (request.Time == "") {
+		http.Error(w, "Expected either session_id or time", http.StatusBadRequest)
+		return
+	}
+
+	trip, err := s.db.GetTrip(request.TripID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var session *models.Session
+	var at time.Time
+	if request.SessionID != 0 {
+		if session, err = s.db.GetSession(request.SessionID); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+	} else {
+		if at, err = time.Parse("2006-01-02T15:04", request.Time); err != nil {
+			if at, err = time.Parse("2006-01-02", request.Time); err != nil {
+				http.Error(w, "Invalid time", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	rule, err := processor.NewSplitRule(*trip, session, at)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.storeTripRule(w, rule)
+}
+
+func (s *Server) handleAPIAttachSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		TripID    int64 `json:"trip_id"`
+		SessionID int64 `json:"session_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	trip, err := s.db.GetTrip(request.TripID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	session, err := s.db.GetSession(request.SessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	rule, err := processor.NewAttachRule(*trip, *session)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.storeTripRule(w, rule)
+}
+
+func (s *Server) handleAPIDetachSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		SessionID int64 `json:"session_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	session, err := s.db.GetSession(request.SessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	rule, err := processor.NewDetachRule(*session)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.storeTripRule(w, rule)
+}
+
+func (s *Server) handleAPIDeleteTripRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.DeleteTripRule(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// storeTripRule saves a rule and responds with it. Rules take effect on the next detect-trips run.
+func (s *Server) storeTripRule(w http.ResponseWriter, rule models.TripRule) {
+	id, err := s.db.StoreTripRule(rule)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rule.ID = id
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
 // handleImmichProxy proxies requests to Immich with authentication
 func (s *Server) handleImmichProxy(w http.ResponseWriter, r *http.Request) {
 	// Extract the path after /api/immich-proxy/
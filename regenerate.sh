@@ -6,14 +6,14 @@ echo "Immich Albums - Full Regeneration Pipeline"
 echo "======================================================"
 echo ""
 echo "This will:"
-echo "  1. Re-import all assets from Immich (2010-01-01 to 2026-01-01)"
+echo "  1. Sync assets from Immich (full import on first run, then only new or changed assets)"
 echo "  2. Label devices (choose: import seeds or use web UI)"
 echo "  3. Re-infer locations (using photographer labels)"
 echo "  4. Re-detect sessions (using photographer labels)"
 echo "  5. Re-detect trips (using sessions)"
 echo "  6. Optionally create albums in Immich"
 echo ""
-echo "⚠️  WARNING: This will clear all existing data and re-fetch from Immich"
+echo "⚠️  WARNING: This will recompute all sessions and trips"
 echo ""
 read -p "Continue? (y/n) " -n 1 -r
 echo
@@ -23,9 +23,9 @@ then
 fi
 
 echo ""
-echo "[1/6] Re-importing assets from Immich..."
+echo "[1/6] Syncing assets from Immich..."
 echo "======================================================"
-./immich-albums discover --start-date 2000-01-01 --end-date 2026-01-01
+./immich-albums discover --sync --start-date 2000-01-01 --end-date 2026-01-01
 if [ $? -ne 0 ]; then
     echo "Error: Asset discovery failed"
     exit 1